@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// MailConfig 邮件发送配置，可通过环境变量覆盖默认值
+type MailConfig struct {
+	// Sender 发送方式：log（只写日志）、file（保存为.eml文件）、smtp
+	Sender string
+	From   string
+	// DefaultLocale 用户未设置语言时使用的邮件语言
+	DefaultLocale string
+	// MaxAttempts 发送失败的最大尝试次数，超过后不再重试
+	MaxAttempts int
+	// RetryBaseDelay 第一次重试的等待时间，之后每次翻倍，最长 RetryMaxDelay
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	FileDir string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	// SMTPTimeout 连接SMTP服务器并发送一封邮件的超时时间
+	SMTPTimeout time.Duration
+
+	// BaseURL 邮件中链接的站点地址
+	BaseURL string
+	// PasswordResetTTL 密码重置链接有效期
+	PasswordResetTTL time.Duration
+}
+
+var Mail = MailConfig{
+	Sender:           "log",
+	From:             "volunteer-system@localhost",
+	DefaultLocale:    "zh-CN",
+	MaxAttempts:      5,
+	RetryBaseDelay:   time.Minute,
+	RetryMaxDelay:    time.Hour,
+	FileDir:          "./mail-outbox",
+	SMTPPort:         587,
+	SMTPTimeout:      30 * time.Second,
+	BaseURL:          "http://localhost:8080",
+	PasswordResetTTL: 30 * time.Minute,
+}
+
+// LoadMail 从环境变量读取邮件配置
+func LoadMail() {
+	Mail.Sender = envString("VOLUNTEER_MAIL_SENDER", Mail.Sender)
+	Mail.From = envString("VOLUNTEER_MAIL_FROM", Mail.From)
+	Mail.DefaultLocale = envString("VOLUNTEER_MAIL_LOCALE", Mail.DefaultLocale)
+	Mail.MaxAttempts = envInt("VOLUNTEER_MAIL_MAX_ATTEMPTS", Mail.MaxAttempts)
+	Mail.RetryBaseDelay = envDuration("VOLUNTEER_MAIL_RETRY_BASE_DELAY", Mail.RetryBaseDelay)
+	Mail.RetryMaxDelay = envDuration("VOLUNTEER_MAIL_RETRY_MAX_DELAY", Mail.RetryMaxDelay)
+	Mail.FileDir = envString("VOLUNTEER_MAIL_DIR", Mail.FileDir)
+	Mail.SMTPHost = envString("VOLUNTEER_SMTP_HOST", Mail.SMTPHost)
+	Mail.SMTPPort = envInt("VOLUNTEER_SMTP_PORT", Mail.SMTPPort)
+	Mail.SMTPUsername = envString("VOLUNTEER_SMTP_USERNAME", Mail.SMTPUsername)
+	Mail.SMTPPassword = envString("VOLUNTEER_SMTP_PASSWORD", Mail.SMTPPassword)
+	Mail.SMTPTimeout = envDuration("VOLUNTEER_SMTP_TIMEOUT", Mail.SMTPTimeout)
+	Mail.BaseURL = envString("VOLUNTEER_BASE_URL", Mail.BaseURL)
+	Mail.PasswordResetTTL = envDuration("VOLUNTEER_PASSWORD_RESET_TTL", Mail.PasswordResetTTL)
+}
+
+func envString(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
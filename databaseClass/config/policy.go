@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PolicyConfig 业务策略配置，可通过环境变量覆盖默认值
+type PolicyConfig struct {
+	// AllowReapplyAfterWithdrawal 用户撤回报名后是否允许重新报名
+	AllowReapplyAfterWithdrawal bool
+	// CheckInCodeTTLMinutes 签到码有效期（分钟）
+	CheckInCodeTTLMinutes int
+	// CheckInOpenMinutes 活动开始前多少分钟开放签到
+	CheckInOpenMinutes int
+	// NoShowThreshold 统计窗口内未到场达到该次数即限制报名，0表示不限制
+	NoShowThreshold int
+	// NoShowWindowDays 未到场次数的统计窗口（天）
+	NoShowWindowDays int
+	// NoShowPenaltyDays 限制报名的天数
+	NoShowPenaltyDays int
+	// ReminderOffsets 活动开始前多久提醒已批准的志愿者（从大到小）
+	ReminderOffsets []time.Duration
+	// RosterSummaryOffset 活动开始前多久向组织者发送名单汇总
+	RosterSummaryOffset time.Duration
+}
+
+var Policy = PolicyConfig{
+	AllowReapplyAfterWithdrawal: true,
+	CheckInCodeTTLMinutes:       5,
+	CheckInOpenMinutes:          60,
+	NoShowThreshold:             3,
+	NoShowWindowDays:            90,
+	NoShowPenaltyDays:           30,
+	ReminderOffsets:             []time.Duration{24 * time.Hour, 2 * time.Hour},
+	RosterSummaryOffset:         24 * time.Hour,
+}
+
+// LoadPolicy 从环境变量读取业务策略
+func LoadPolicy() {
+	Policy.AllowReapplyAfterWithdrawal = envBool("VOLUNTEER_ALLOW_REAPPLY", Policy.AllowReapplyAfterWithdrawal)
+	Policy.CheckInCodeTTLMinutes = envInt("VOLUNTEER_CHECKIN_CODE_TTL_MINUTES", Policy.CheckInCodeTTLMinutes)
+	Policy.CheckInOpenMinutes = envInt("VOLUNTEER_CHECKIN_OPEN_MINUTES", Policy.CheckInOpenMinutes)
+	Policy.NoShowThreshold = envInt("VOLUNTEER_NO_SHOW_THRESHOLD", Policy.NoShowThreshold)
+	Policy.NoShowWindowDays = envInt("VOLUNTEER_NO_SHOW_WINDOW_DAYS", Policy.NoShowWindowDays)
+	Policy.NoShowPenaltyDays = envInt("VOLUNTEER_NO_SHOW_PENALTY_DAYS", Policy.NoShowPenaltyDays)
+	Policy.ReminderOffsets = envDurations("VOLUNTEER_REMINDER_OFFSETS", Policy.ReminderOffsets)
+	Policy.RosterSummaryOffset = envDuration("VOLUNTEER_ROSTER_SUMMARY_OFFSET", Policy.RosterSummaryOffset)
+}
+
+func envBool(key string, fallback bool) bool {
+	if v, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return v
+	}
+	return fallback
+}
+
+func envInt(key string, fallback int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return v
+	}
+	return fallback
+}
+
+// envDurations 读取逗号分隔的时长列表（如 "24h,2h"），按从大到小排序
+func envDurations(key string, fallback []time.Duration) []time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+
+	var durations []time.Duration
+	for _, part := range strings.Split(raw, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || d <= 0 {
+			return fallback
+		}
+		durations = append(durations, d)
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] > durations[j] })
+	return durations
+}
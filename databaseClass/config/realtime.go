@@ -0,0 +1,26 @@
+package config
+
+import "time"
+
+// RealtimeConfig 实时推送配置，可通过环境变量覆盖默认值
+type RealtimeConfig struct {
+	// PollInterval 各实例轮询共享事件表的间隔
+	PollInterval time.Duration
+	// GapTimeout 事件ID出现空缺时等待较早事务补写的时间，超时视为该ID不存在
+	GapTimeout time.Duration
+	// Retention 事件在共享表中保留的时间，过期由后台任务清理
+	Retention time.Duration
+}
+
+var Realtime = RealtimeConfig{
+	PollInterval: time.Second,
+	GapTimeout:   10 * time.Second,
+	Retention:    10 * time.Minute,
+}
+
+// LoadRealtime 从环境变量读取实时推送配置
+func LoadRealtime() {
+	Realtime.PollInterval = envDuration("VOLUNTEER_REALTIME_POLL_INTERVAL", Realtime.PollInterval)
+	Realtime.GapTimeout = envDuration("VOLUNTEER_REALTIME_GAP_TIMEOUT", Realtime.GapTimeout)
+	Realtime.Retention = envDuration("VOLUNTEER_REALTIME_RETENTION", Realtime.Retention)
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// SchedulerConfig 后台定时任务配置，可通过环境变量覆盖默认值
+type SchedulerConfig struct {
+	// Enabled 是否在本实例启动定时任务
+	Enabled bool
+	// DefaultInterval 未单独配置的任务的执行间隔
+	DefaultInterval time.Duration
+	// DefaultTimeout 未单独配置的任务单次执行的超时时间
+	DefaultTimeout time.Duration
+	// LeaderLockName 多实例选举任务领导者使用的MySQL命名锁
+	LeaderLockName string
+	// LeaderRetryInterval 非领导者实例竞选、领导者确认锁的间隔
+	LeaderRetryInterval time.Duration
+}
+
+var Scheduler = SchedulerConfig{
+	Enabled:             true,
+	DefaultInterval:     time.Minute,
+	DefaultTimeout:      5 * time.Minute,
+	LeaderLockName:      "volunteer-system:scheduler",
+	LeaderRetryInterval: 10 * time.Second,
+}
+
+// LoadScheduler 从环境变量读取定时任务配置
+func LoadScheduler() {
+	Scheduler.Enabled = envBool("VOLUNTEER_SCHEDULER_ENABLED", Scheduler.Enabled)
+	Scheduler.DefaultInterval = envDuration("VOLUNTEER_JOB_INTERVAL", Scheduler.DefaultInterval)
+	Scheduler.DefaultTimeout = envDuration("VOLUNTEER_JOB_TIMEOUT", Scheduler.DefaultTimeout)
+	if v := os.Getenv("VOLUNTEER_SCHEDULER_LOCK_NAME"); v != "" {
+		Scheduler.LeaderLockName = v
+	}
+	Scheduler.LeaderRetryInterval = envDuration("VOLUNTEER_LEADER_RETRY_INTERVAL", Scheduler.LeaderRetryInterval)
+}
+
+// JobInterval 任务的执行间隔，可用 VOLUNTEER_JOB_INTERVAL_<任务名大写> 单独配置，
+// 如 VOLUNTEER_JOB_INTERVAL_EXPIRE_ACTIVITIES=30s
+func JobInterval(name string) time.Duration {
+	return envDuration("VOLUNTEER_JOB_INTERVAL_"+strings.ToUpper(name), Scheduler.DefaultInterval)
+}
+
+// JobTimeout 任务单次执行的超时时间，可用 VOLUNTEER_JOB_TIMEOUT_<任务名大写> 单独配置
+func JobTimeout(name string) time.Duration {
+	return envDuration("VOLUNTEER_JOB_TIMEOUT_"+strings.ToUpper(name), Scheduler.DefaultTimeout)
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return fallback
+}
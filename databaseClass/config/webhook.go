@@ -0,0 +1,29 @@
+package config
+
+import "time"
+
+// WebhookConfig Webhook投递配置，可通过环境变量覆盖默认值
+type WebhookConfig struct {
+	// Timeout 单次请求超时时间
+	Timeout time.Duration
+	// MaxAttempts 最大尝试次数，超过后标记为失败，可由管理员重放
+	MaxAttempts int
+	// RetryBaseDelay 第一次重试的等待时间，之后每次翻倍，最长 RetryMaxDelay
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+}
+
+var Webhook = WebhookConfig{
+	Timeout:        10 * time.Second,
+	MaxAttempts:    8,
+	RetryBaseDelay: time.Minute,
+	RetryMaxDelay:  2 * time.Hour,
+}
+
+// LoadWebhook 从环境变量读取Webhook配置
+func LoadWebhook() {
+	Webhook.Timeout = envDuration("VOLUNTEER_WEBHOOK_TIMEOUT", Webhook.Timeout)
+	Webhook.MaxAttempts = envInt("VOLUNTEER_WEBHOOK_MAX_ATTEMPTS", Webhook.MaxAttempts)
+	Webhook.RetryBaseDelay = envDuration("VOLUNTEER_WEBHOOK_RETRY_BASE_DELAY", Webhook.RetryBaseDelay)
+	Webhook.RetryMaxDelay = envDuration("VOLUNTEER_WEBHOOK_RETRY_MAX_DELAY", Webhook.RetryMaxDelay)
+}
@@ -52,3 +52,526 @@ CREATE INDEX idx_status_log_application ON ApplicationStatusLog(application_id);
 -- SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, COLUMN_COMMENT 
 -- FROM INFORMATION_SCHEMA.COLUMNS 
 -- WHERE TABLE_NAME='Activity' AND TABLE_SCHEMA=DATABASE();
+
+-- ============================================================
+-- 8. 活动报名资格规则
+-- ============================================================
+-- 资格规则以JSON数组存储，例如：
+-- [{"type":"dept","dept_ids":[1,2]},{"type":"min_completed","min_completed":3},{"type":"training","training":"急救培训"}]
+ALTER TABLE Activity ADD COLUMN eligibility_rules TEXT NULL COMMENT '报名资格规则(JSON)';
+
+-- 用户所属部门（部门规则）
+ALTER TABLE User ADD COLUMN dept_id INT NULL COMMENT '所属部门';
+ALTER TABLE User ADD CONSTRAINT fk_user_dept
+    FOREIGN KEY (dept_id) REFERENCES Dept (dept_id);
+
+-- 用户培训记录（培训规则）
+CREATE TABLE UserTraining
+(
+   training_id          INT NOT NULL AUTO_INCREMENT,
+   user_id              INT NOT NULL,
+   training_name        VARCHAR(100) NOT NULL,
+   completed_at         DATETIME NOT NULL,
+   PRIMARY KEY (training_id),
+   KEY idx_training_user (user_id),
+   CONSTRAINT fk_training_user FOREIGN KEY (user_id) REFERENCES User (user_id)
+);
+
+-- ============================================================
+-- 9. 活动自定义报名表单
+-- ============================================================
+-- 表单定义以JSON数组存储，字段类型: text / single_choice / multi_choice / number / date，例如：
+-- [{"key":"tshirt","label":"T恤尺码","type":"single_choice","required":true,"options":["S","M","L"]}]
+ALTER TABLE Activity ADD COLUMN form_schema TEXT NULL COMMENT '报名表单定义(JSON)';
+-- 报名时填写的回答，键为表单字段key
+ALTER TABLE Application ADD COLUMN answers TEXT NULL COMMENT '报名表单回答(JSON)';
+
+-- ============================================================
+-- 10. 审核意见与拒绝原因
+-- ============================================================
+ALTER TABLE ApplicationStatusLog ADD COLUMN comment VARCHAR(500) NULL COMMENT '审核意见/拒绝原因';
+-- 系统说明（如管理员忽略时间冲突）单独保存，只在管理端显示，报名者看不到
+ALTER TABLE ApplicationStatusLog ADD COLUMN internal_note VARCHAR(255) NOT NULL DEFAULT '' COMMENT '系统说明，仅管理端可见';
+
+-- ============================================================
+-- 11. 报名状态约束（需 MySQL 8.0.16 及以上才会强制检查）
+-- ============================================================
+-- 状态流转：pending -> approved / rejected / waitlisted / withdrawn / cancelled_by_organizer
+--           waitlisted -> approved / rejected / withdrawn / cancelled_by_organizer
+--           approved -> withdrawn / cancelled_by_organizer / attended / no_show
+--           attended <-> no_show（管理员更正考勤）
+-- cancelled_by_organizer 有22个字符，先加宽状态列
+ALTER TABLE Application MODIFY current_status VARCHAR(32) NOT NULL DEFAULT 'pending';
+ALTER TABLE ApplicationStatusLog MODIFY log_status VARCHAR(32) NOT NULL;
+ALTER TABLE Application ADD CONSTRAINT chk_application_status CHECK (current_status IN
+    ('pending', 'approved', 'rejected', 'withdrawn', 'cancelled_by_organizer', 'waitlisted', 'attended', 'no_show'));
+ALTER TABLE ApplicationStatusLog ADD CONSTRAINT chk_status_log_status CHECK (log_status IN
+    ('pending', 'approved', 'rejected', 'withdrawn', 'cancelled_by_organizer', 'waitlisted', 'attended', 'no_show'));
+
+-- ============================================================
+-- 12. 签到签退与考勤
+-- ============================================================
+ALTER TABLE Application ADD COLUMN attendance_status VARCHAR(20) NOT NULL DEFAULT '' COMMENT '考勤状态: 空(未记录), checked_in, checked_out, confirmed, absent';
+
+-- 组织者生成的签到/签退码，新码生成后旧码立即失效
+CREATE TABLE CheckInCode
+(
+   code_id              INT NOT NULL AUTO_INCREMENT,
+   activity_id          INT NOT NULL,
+   code                 VARCHAR(16) NOT NULL,
+   purpose              VARCHAR(20) NOT NULL COMMENT 'check_in / check_out',
+   created_by           INT NOT NULL,
+   created_at           DATETIME NOT NULL,
+   expires_at           DATETIME NOT NULL,
+   PRIMARY KEY (code_id),
+   KEY idx_checkin_code_lookup (activity_id, purpose, code),
+   CONSTRAINT fk_checkin_code_activity FOREIGN KEY (activity_id) REFERENCES Activity (activity_id),
+   CONSTRAINT fk_checkin_code_creator FOREIGN KEY (created_by) REFERENCES User (user_id)
+);
+
+-- 每条报名最多一条考勤记录
+CREATE TABLE Attendance
+(
+   attendance_id        INT NOT NULL AUTO_INCREMENT,
+   application_id       INT NOT NULL,
+   activity_id          INT NOT NULL,
+   user_id              INT NOT NULL,
+   check_in_time        DATETIME NULL,
+   check_out_time       DATETIME NULL,
+   check_in_code_id     INT NULL,
+   method               VARCHAR(20) NOT NULL COMMENT 'code(扫码) / manual(管理员登记)',
+   marked_by            INT NULL,
+   updated_at           DATETIME NOT NULL,
+   PRIMARY KEY (attendance_id),
+   UNIQUE KEY uk_attendance_application (application_id),
+   KEY idx_attendance_activity (activity_id),
+   CONSTRAINT fk_attendance_application FOREIGN KEY (application_id) REFERENCES Application (application_id),
+   CONSTRAINT fk_attendance_code FOREIGN KEY (check_in_code_id) REFERENCES CheckInCode (code_id),
+   CONSTRAINT fk_attendance_marker FOREIGN KEY (marked_by) REFERENCES User (user_id)
+);
+
+-- ============================================================
+-- 13. 志愿服务时长台账
+-- ============================================================
+ALTER TABLE Activity ADD COLUMN duration_minutes INT NOT NULL DEFAULT 120 COMMENT '活动时长(分钟)';
+
+-- 每条报名最多一条自动计算记录(attendance/duration)，管理员调整(adjustment)另行追加
+CREATE TABLE ServiceHoursEntry
+(
+   entry_id             INT NOT NULL AUTO_INCREMENT,
+   user_id              INT NOT NULL,
+   application_id       INT NULL,
+   activity_id          INT NULL,
+   hours                DECIMAL(6,2) NOT NULL,
+   source               VARCHAR(20) NOT NULL COMMENT 'attendance(签到签退) / duration(活动时长) / adjustment(管理员调整)',
+   reason               VARCHAR(500) NULL,
+   created_by           INT NULL,
+   created_at           DATETIME NOT NULL,
+   PRIMARY KEY (entry_id),
+   KEY idx_hours_user (user_id),
+   KEY idx_hours_activity (activity_id),
+   KEY idx_hours_application (application_id),
+   CONSTRAINT fk_hours_user FOREIGN KEY (user_id) REFERENCES User (user_id),
+   CONSTRAINT fk_hours_application FOREIGN KEY (application_id) REFERENCES Application (application_id),
+   CONSTRAINT fk_hours_activity FOREIGN KEY (activity_id) REFERENCES Activity (activity_id),
+   CONSTRAINT fk_hours_creator FOREIGN KEY (created_by) REFERENCES User (user_id)
+);
+
+-- ============================================================
+-- 14. 志愿服务证明与服务记录
+-- ============================================================
+-- 内容（活动数、时长）未变化时重复下载沿用同一验证码
+CREATE TABLE Certificate
+(
+   certificate_id       INT NOT NULL AUTO_INCREMENT,
+   verification_code    VARCHAR(32) NOT NULL,
+   type                 VARCHAR(20) NOT NULL COMMENT 'certificate(单次活动证明) / transcript(服务记录)',
+   user_id              INT NOT NULL,
+   application_id       INT NULL,
+   activity_count       INT NOT NULL,
+   total_hours          DECIMAL(8,2) NOT NULL,
+   issued_at            DATETIME NOT NULL,
+   PRIMARY KEY (certificate_id),
+   UNIQUE KEY uk_certificate_code (verification_code),
+   KEY idx_certificate_user (user_id),
+   CONSTRAINT fk_certificate_user FOREIGN KEY (user_id) REFERENCES User (user_id),
+   CONSTRAINT fk_certificate_application FOREIGN KEY (application_id) REFERENCES Application (application_id)
+);
+
+-- ============================================================
+-- 15. 未到场记录与报名限制
+-- ============================================================
+-- 一定时间内未到场次数达到阈值后自动生成，到期或被管理员解除后失效
+CREATE TABLE ParticipationPenalty
+(
+   penalty_id           INT NOT NULL AUTO_INCREMENT,
+   user_id              INT NOT NULL,
+   no_show_count        INT NOT NULL,
+   reason               VARCHAR(200) NOT NULL,
+   start_time           DATETIME NOT NULL,
+   end_time             DATETIME NOT NULL,
+   lifted_at            DATETIME NULL,
+   lifted_by            INT NULL,
+   lift_reason          VARCHAR(500) NOT NULL DEFAULT '',
+   PRIMARY KEY (penalty_id),
+   KEY idx_penalty_user_end (user_id, end_time),
+   CONSTRAINT fk_penalty_user FOREIGN KEY (user_id) REFERENCES User (user_id),
+   CONSTRAINT fk_penalty_lifter FOREIGN KEY (lifted_by) REFERENCES User (user_id)
+);
+
+-- ============================================================
+-- 16. 活动审核方式
+-- ============================================================
+-- manual: 人工审核；auto_until_full: 名额未满自动批准；auto_criteria: 满足条件自动批准；lottery: 截止后抽签
+ALTER TABLE Activity ADD COLUMN approval_mode VARCHAR(20) NOT NULL DEFAULT 'manual' COMMENT '审核方式';
+ALTER TABLE Activity ADD COLUMN auto_approve_rules TEXT NULL COMMENT '自动批准条件(JSON)，规则格式同eligibility_rules';
+ALTER TABLE Activity ADD CONSTRAINT chk_activity_approval_mode CHECK (approval_mode IN
+   ('manual', 'auto_until_full', 'auto_criteria', 'lottery'));
+
+-- ============================================================
+-- 17. 报名截止与抽签
+-- ============================================================
+ALTER TABLE Activity ADD COLUMN registration_deadline DATETIME NULL COMMENT '报名截止时间，抽签模式必填';
+
+-- 每个活动只抽签一次；按application_id排序后用seed打乱即可复现抽签顺位
+CREATE TABLE LotteryDraw
+(
+   draw_id              INT NOT NULL AUTO_INCREMENT,
+   activity_id          INT NOT NULL,
+   seed                 BIGINT NOT NULL,
+   applicant_count      INT NOT NULL,
+   approved_count       INT NOT NULL,
+   waitlisted_count     INT NOT NULL,
+   drawn_by             INT NULL COMMENT '为空表示定时任务自动抽签',
+   drawn_at             DATETIME NOT NULL,
+   PRIMARY KEY (draw_id),
+   UNIQUE KEY uk_lottery_activity (activity_id),
+   CONSTRAINT fk_lottery_activity FOREIGN KEY (activity_id) REFERENCES Activity (activity_id),
+   CONSTRAINT fk_lottery_drawer FOREIGN KEY (drawn_by) REFERENCES User (user_id)
+);
+
+CREATE TABLE LotteryDrawEntry
+(
+   entry_id             INT NOT NULL AUTO_INCREMENT,
+   draw_id              INT NOT NULL,
+   application_id       INT NOT NULL,
+   user_id              INT NOT NULL,
+   position             INT NOT NULL,
+   result               VARCHAR(20) NOT NULL COMMENT 'approved / waitlisted',
+   PRIMARY KEY (entry_id),
+   UNIQUE KEY uk_lottery_entry_position (draw_id, position),
+   CONSTRAINT fk_lottery_entry_draw FOREIGN KEY (draw_id) REFERENCES LotteryDraw (draw_id),
+   CONSTRAINT fk_lottery_entry_application FOREIGN KEY (application_id) REFERENCES Application (application_id),
+   CONSTRAINT fk_lottery_entry_user FOREIGN KEY (user_id) REFERENCES User (user_id)
+);
+
+-- ============================================================
+-- 18. 志愿团队与团队报名
+-- ============================================================
+CREATE TABLE Team
+(
+   team_id              INT NOT NULL AUTO_INCREMENT,
+   name                 VARCHAR(100) NOT NULL,
+   leader_id            INT NOT NULL,
+   created_at           DATETIME NOT NULL,
+   PRIMARY KEY (team_id),
+   CONSTRAINT fk_team_leader FOREIGN KEY (leader_id) REFERENCES User (user_id)
+);
+
+CREATE TABLE TeamMember
+(
+   team_id              INT NOT NULL,
+   user_id              INT NOT NULL,
+   joined_at            DATETIME NOT NULL,
+   PRIMARY KEY (team_id, user_id),
+   KEY idx_team_member_user (user_id),
+   CONSTRAINT fk_team_member_team FOREIGN KEY (team_id) REFERENCES Team (team_id),
+   CONSTRAINT fk_team_member_user FOREIGN KEY (user_id) REFERENCES User (user_id)
+);
+
+-- 团队报名整体审核、整体取消；每个成员在Application中各有一条关联的报名
+CREATE TABLE TeamApplication
+(
+   team_application_id  INT NOT NULL AUTO_INCREMENT,
+   team_id              INT NOT NULL,
+   activity_id          INT NOT NULL,
+   applicant_id         INT NOT NULL COMMENT '提交报名的队长',
+   apply_time           DATETIME NOT NULL,
+   PRIMARY KEY (team_application_id),
+   KEY idx_team_application_activity (activity_id),
+   CONSTRAINT fk_team_application_team FOREIGN KEY (team_id) REFERENCES Team (team_id),
+   CONSTRAINT fk_team_application_activity FOREIGN KEY (activity_id) REFERENCES Activity (activity_id),
+   CONSTRAINT fk_team_application_applicant FOREIGN KEY (applicant_id) REFERENCES User (user_id)
+);
+
+ALTER TABLE Application ADD COLUMN team_application_id INT NULL COMMENT '团队报名，个人报名为空';
+ALTER TABLE Application ADD CONSTRAINT fk_application_team
+   FOREIGN KEY (team_application_id) REFERENCES TeamApplication (team_application_id);
+
+-- ============================================================
+-- 19. 活动评分与志愿者评价
+-- ============================================================
+-- 参加了活动的志愿者为活动评分（1-5分），每条报名一条，可修改
+CREATE TABLE ActivityFeedback
+(
+   feedback_id          INT NOT NULL AUTO_INCREMENT,
+   application_id       INT NOT NULL,
+   activity_id          INT NOT NULL,
+   user_id              INT NOT NULL,
+   rating               TINYINT NOT NULL,
+   comment              VARCHAR(500) NOT NULL DEFAULT '',
+   created_at           DATETIME NOT NULL,
+   updated_at           DATETIME NOT NULL,
+   PRIMARY KEY (feedback_id),
+   UNIQUE KEY uk_feedback_application (application_id),
+   KEY idx_feedback_activity (activity_id),
+   CONSTRAINT chk_feedback_rating CHECK (rating BETWEEN 1 AND 5),
+   CONSTRAINT fk_feedback_application FOREIGN KEY (application_id) REFERENCES Application (application_id),
+   CONSTRAINT fk_feedback_activity FOREIGN KEY (activity_id) REFERENCES Activity (activity_id),
+   CONSTRAINT fk_feedback_user FOREIGN KEY (user_id) REFERENCES User (user_id)
+);
+
+-- 组织者对志愿者的评价，仅管理员可查看
+CREATE TABLE VolunteerRating
+(
+   rating_id            INT NOT NULL AUTO_INCREMENT,
+   application_id       INT NOT NULL,
+   activity_id          INT NOT NULL,
+   user_id              INT NOT NULL,
+   rater_id             INT NOT NULL,
+   rating               TINYINT NOT NULL,
+   comment              VARCHAR(500) NOT NULL DEFAULT '',
+   created_at           DATETIME NOT NULL,
+   updated_at           DATETIME NOT NULL,
+   PRIMARY KEY (rating_id),
+   UNIQUE KEY uk_volunteer_rating_application (application_id),
+   KEY idx_volunteer_rating_user (user_id),
+   CONSTRAINT chk_volunteer_rating CHECK (rating BETWEEN 1 AND 5),
+   CONSTRAINT fk_volunteer_rating_application FOREIGN KEY (application_id) REFERENCES Application (application_id),
+   CONSTRAINT fk_volunteer_rating_activity FOREIGN KEY (activity_id) REFERENCES Activity (activity_id),
+   CONSTRAINT fk_volunteer_rating_user FOREIGN KEY (user_id) REFERENCES User (user_id),
+   CONSTRAINT fk_volunteer_rating_rater FOREIGN KEY (rater_id) REFERENCES User (user_id)
+);
+
+-- ============================================================
+-- 20. 后台任务运行记录与暂停状态
+-- ============================================================
+
+-- 每次任务运行一条记录（定时调度或管理员手动触发）
+CREATE TABLE JobRun
+(
+   run_id               BIGINT NOT NULL AUTO_INCREMENT,
+   job_name             VARCHAR(64) NOT NULL,
+   trigger_type         VARCHAR(20) NOT NULL,
+   triggered_by         INT,
+   status               VARCHAR(20) NOT NULL,
+   started_at           DATETIME NOT NULL,
+   finished_at          DATETIME,
+   affected_rows        BIGINT NOT NULL DEFAULT 0,
+   error_message        VARCHAR(1000) NOT NULL DEFAULT '',
+   PRIMARY KEY (run_id),
+   KEY idx_job_run_job (job_name, run_id),
+   CONSTRAINT chk_job_run_status CHECK (status IN ('running', 'succeeded', 'failed')),
+   CONSTRAINT chk_job_run_trigger CHECK (trigger_type IN ('schedule', 'manual')),
+   CONSTRAINT fk_job_run_user FOREIGN KEY (triggered_by) REFERENCES User (user_id)
+);
+
+-- 任务暂停状态，所有实例共享
+CREATE TABLE JobState
+(
+   job_name             VARCHAR(64) NOT NULL,
+   paused               BOOLEAN NOT NULL DEFAULT FALSE,
+   updated_by           INT,
+   updated_at           DATETIME NOT NULL,
+   PRIMARY KEY (job_name),
+   CONSTRAINT fk_job_state_user FOREIGN KEY (updated_by) REFERENCES User (user_id)
+);
+
+-- ============================================================
+-- 21. 活动过期时关闭未审核的报名
+-- ============================================================
+
+ALTER TABLE Application DROP CHECK chk_application_status;
+ALTER TABLE Application ADD CONSTRAINT chk_application_status CHECK (current_status IN
+    ('pending', 'approved', 'rejected', 'withdrawn', 'cancelled_by_organizer', 'waitlisted', 'attended', 'no_show', 'expired'));
+ALTER TABLE ApplicationStatusLog DROP CHECK chk_status_log_status;
+ALTER TABLE ApplicationStatusLog ADD CONSTRAINT chk_status_log_status CHECK (log_status IN
+    ('pending', 'approved', 'rejected', 'withdrawn', 'cancelled_by_organizer', 'waitlisted', 'attended', 'no_show', 'expired'));
+
+-- ============================================================
+-- 22. 定时发布与自动关闭报名
+-- ============================================================
+
+-- 活动状态: scheduled(待发布), active(报名中), registration_closed(报名已关闭), expired(已过期), closed(已关闭)
+ALTER TABLE Activity ADD COLUMN publish_at DATETIME NULL COMMENT '定时发布时间，为空则立即发布';
+ALTER TABLE Activity MODIFY COLUMN status VARCHAR(20) DEFAULT 'active'
+    COMMENT '活动状态: scheduled(待发布), active(报名中), registration_closed(报名已关闭), expired(已过期), closed(已关闭)';
+CREATE INDEX idx_activity_publish_at ON Activity(status, publish_at);
+
+-- ============================================================
+-- 23. 活动开始前提醒
+-- ============================================================
+
+-- 已发送的提醒：志愿者提醒每个报名每个提醒时间点一条，组织者名单汇总每个活动一条
+CREATE TABLE ReminderLog
+(
+   reminder_id          INT NOT NULL AUTO_INCREMENT,
+   reminder_type        VARCHAR(20) NOT NULL,
+   activity_id          INT NOT NULL,
+   user_id              INT NOT NULL,
+   application_id       INT,
+   offset_minutes       INT NOT NULL,
+   sent_at              DATETIME NOT NULL,
+   PRIMARY KEY (reminder_id),
+   UNIQUE KEY uk_reminder (reminder_type, activity_id, user_id, offset_minutes),
+   KEY idx_reminder_application (application_id),
+   CONSTRAINT chk_reminder_type CHECK (reminder_type IN ('volunteer', 'roster')),
+   CONSTRAINT fk_reminder_activity FOREIGN KEY (activity_id) REFERENCES Activity (activity_id),
+   CONSTRAINT fk_reminder_user FOREIGN KEY (user_id) REFERENCES User (user_id),
+   CONSTRAINT fk_reminder_application FOREIGN KEY (application_id) REFERENCES Application (application_id)
+);
+
+-- ============================================================
+-- 24. 站内通知
+-- ============================================================
+
+-- 活动删除时通知保留，activity_id / application_id 置空
+CREATE TABLE Notification
+(
+   notification_id      INT NOT NULL AUTO_INCREMENT,
+   user_id              INT NOT NULL,
+   title                VARCHAR(100) NOT NULL,
+   content              TEXT,
+   activity_id          INT,
+   application_id       INT,
+   is_read              BOOLEAN NOT NULL DEFAULT FALSE,
+   created_at           DATETIME NOT NULL,
+   read_at              DATETIME,
+   PRIMARY KEY (notification_id),
+   KEY idx_notification_user (user_id, is_read, notification_id),
+   CONSTRAINT fk_notification_user FOREIGN KEY (user_id) REFERENCES User (user_id),
+   CONSTRAINT fk_notification_activity FOREIGN KEY (activity_id) REFERENCES Activity (activity_id),
+   CONSTRAINT fk_notification_application FOREIGN KEY (application_id) REFERENCES Application (application_id)
+);
+
+-- ============================================================
+-- 25. 邮件发送与密码重置
+-- ============================================================
+
+ALTER TABLE User ADD COLUMN email VARCHAR(255) NOT NULL DEFAULT '' COMMENT '通知邮箱，为空则不发邮件';
+ALTER TABLE User ADD COLUMN locale VARCHAR(10) NOT NULL DEFAULT '' COMMENT '邮件语言: zh-CN, en-US，为空使用系统默认';
+
+-- 待发送邮件，业务操作在同一事务中写入，后台任务投递并按指数退避重试
+CREATE TABLE EmailOutbox
+(
+   email_id             BIGINT NOT NULL AUTO_INCREMENT,
+   user_id              INT,
+   to_address           VARCHAR(255) NOT NULL,
+   template             VARCHAR(50) NOT NULL,
+   subject              VARCHAR(255) NOT NULL,
+   body                 TEXT,
+   status               VARCHAR(20) NOT NULL DEFAULT 'pending',
+   attempts             INT NOT NULL DEFAULT 0,
+   next_attempt_at      DATETIME NOT NULL,
+   last_error           VARCHAR(1000) NOT NULL DEFAULT '',
+   created_at           DATETIME NOT NULL,
+   sent_at              DATETIME,
+   PRIMARY KEY (email_id),
+   KEY idx_email_outbox_due (status, next_attempt_at),
+   CONSTRAINT chk_email_outbox_status CHECK (status IN ('pending', 'sent', 'failed')),
+   CONSTRAINT fk_email_outbox_user FOREIGN KEY (user_id) REFERENCES User (user_id)
+);
+
+-- 密码重置令牌（只保存SHA-256摘要）
+CREATE TABLE PasswordResetToken
+(
+   token_id             INT NOT NULL AUTO_INCREMENT,
+   user_id              INT NOT NULL,
+   token_hash           CHAR(64) NOT NULL,
+   expires_at           DATETIME NOT NULL,
+   used_at              DATETIME,
+   created_at           DATETIME NOT NULL,
+   PRIMARY KEY (token_id),
+   UNIQUE KEY uk_password_reset_token (token_hash),
+   CONSTRAINT fk_password_reset_user FOREIGN KEY (user_id) REFERENCES User (user_id)
+);
+
+-- ============================================================
+-- 26. Webhook
+-- ============================================================
+
+CREATE TABLE WebhookSubscription
+(
+   subscription_id      INT NOT NULL AUTO_INCREMENT,
+   url                  VARCHAR(500) NOT NULL,
+   events               TEXT NOT NULL COMMENT '订阅的事件（JSON数组），"*"表示全部',
+   secret               VARCHAR(128) NOT NULL,
+   active               BOOLEAN NOT NULL DEFAULT TRUE,
+   created_by           INT NOT NULL,
+   created_at           DATETIME NOT NULL,
+   PRIMARY KEY (subscription_id),
+   CONSTRAINT fk_webhook_creator FOREIGN KEY (created_by) REFERENCES User (user_id)
+);
+
+-- 每个事件对每个订阅一条投递记录，业务操作在同一事务中写入
+CREATE TABLE WebhookDelivery
+(
+   delivery_id          BIGINT NOT NULL AUTO_INCREMENT,
+   subscription_id      INT NOT NULL,
+   event_id             CHAR(32) NOT NULL,
+   event                VARCHAR(50) NOT NULL,
+   payload              TEXT NOT NULL,
+   status               VARCHAR(20) NOT NULL DEFAULT 'pending',
+   attempts             INT NOT NULL DEFAULT 0,
+   next_attempt_at      DATETIME NOT NULL,
+   last_status_code     INT NOT NULL DEFAULT 0,
+   last_error           VARCHAR(1000) NOT NULL DEFAULT '',
+   created_at           DATETIME NOT NULL,
+   delivered_at         DATETIME,
+   PRIMARY KEY (delivery_id),
+   KEY idx_webhook_delivery_due (status, next_attempt_at),
+   KEY idx_webhook_delivery_subscription (subscription_id, status),
+   CONSTRAINT chk_webhook_delivery_status CHECK (status IN ('pending', 'succeeded', 'failed')),
+   CONSTRAINT fk_webhook_delivery_subscription FOREIGN KEY (subscription_id) REFERENCES WebhookSubscription (subscription_id)
+);
+
+-- 每次投递尝试的结果
+CREATE TABLE WebhookAttempt
+(
+   attempt_id           BIGINT NOT NULL AUTO_INCREMENT,
+   delivery_id          BIGINT NOT NULL,
+   attempted_at         DATETIME NOT NULL,
+   status_code          INT NOT NULL DEFAULT 0,
+   error                VARCHAR(1000) NOT NULL DEFAULT '',
+   duration_ms          BIGINT NOT NULL,
+   PRIMARY KEY (attempt_id),
+   KEY idx_webhook_attempt_delivery (delivery_id),
+   CONSTRAINT fk_webhook_attempt_delivery FOREIGN KEY (delivery_id) REFERENCES WebhookDelivery (delivery_id)
+);
+
+-- ============================================================
+-- 27. 清除已投递的密码重置邮件正文
+-- ============================================================
+
+-- 重置链接含明文令牌，投递结束（已发送或已失败）后不再保留
+UPDATE EmailOutbox SET body = '（正文含一次性凭据，已在投递结束后清除）'
+WHERE template = 'password_reset' AND status IN ('sent', 'failed');
+
+-- ============================================================
+-- 28. 实时事件多实例分发
+-- ============================================================
+
+-- 业务事务提交后写入，每个实例轮询新事件并推送给连接在本实例上的客户端；
+-- 过期事件由后台任务（prune_realtime_events）清理
+CREATE TABLE RealtimeEvent
+(
+   event_id             BIGINT NOT NULL AUTO_INCREMENT,
+   event_type           VARCHAR(50) NOT NULL,
+   payload              TEXT NOT NULL,
+   user_id              INT,
+   admins               BOOLEAN NOT NULL DEFAULT FALSE,
+   broadcast            BOOLEAN NOT NULL DEFAULT FALSE,
+   created_at           DATETIME NOT NULL,
+   PRIMARY KEY (event_id),
+   KEY idx_realtime_event_created (created_at)
+);
@@ -215,3 +215,38 @@ func GetAvailableActivities(c *gin.Context) {
 		"data":    activities,
 	})
 }
+
+// GetActivityEligibility 说明用户能否报名该活动及未通过的规则
+func GetActivityEligibility(c *gin.Context) {
+	activityID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "活动ID格式不正确",
+		})
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "用户ID格式不正确",
+		})
+		return
+	}
+
+	result, err := service.ExplainEligibility(userID, activityID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -30,18 +32,29 @@ func ApplyActivity(c *gin.Context) {
 		return
 	}
 
-	application, err := service.ApplyActivity(req.UserID, activityID)
+	application, err := service.ApplyActivity(activityID, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": err.Error(),
-		})
+		var eligibilityErr *service.EligibilityError
+		if errors.As(err, &eligibilityErr) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"message": err.Error(),
+				"data":    eligibilityErr.Result,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
+	message := "报名成功，等待管理员审核"
+	if application.CurrentStatus == model.AppStatusApproved {
+		message = "报名成功，已自动通过审核"
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "报名成功，等待管理员审核",
+		"message": message,
 		"data":    application,
 	})
 }
@@ -118,22 +131,43 @@ func UpdateApplicationStatus(c *gin.Context) {
 		return
 	}
 
-	if err := service.UpdateApplicationStatus(appID, req.Status, req.HandlerID); err != nil {
+	if err := service.UpdateApplicationStatus(appID, req.Status, req.HandlerID, req.Comment, req.OverrideConflict); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "更新报名状态成功",
+	})
+}
+
+// BulkReviewApplications 批量批准或拒绝报名，返回每条报名的处理结果
+func BulkReviewApplications(c *gin.Context) {
+	var req model.BulkReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
-			"message": err.Error(),
+			"message": "请求数据格式错误",
 		})
 		return
 	}
 
+	result, err := service.BulkReviewApplications(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "更新报名状态成功",
+		"message": fmt.Sprintf("批量审核完成：成功 %d 条，失败 %d 条", result.Succeeded, result.Failed),
+		"data":    result,
 	})
 }
 
-// CancelApplication 取消报名
-func CancelApplication(c *gin.Context) {
+// GetApplicationTimeline 获取报名的状态变更历史
+func GetApplicationTimeline(c *gin.Context) {
 	appIDStr := c.Param("applicationId")
 	appID, err := strconv.Atoi(appIDStr)
 	if err != nil {
@@ -144,7 +178,22 @@ func CancelApplication(c *gin.Context) {
 		return
 	}
 
-	if err := service.CancelApplication(appID); err != nil {
+	// 带管理员的 handler_id 时同时返回系统说明
+	var viewerID *int
+	if raw := c.Query("handler_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "handler_id格式不正确",
+			})
+			return
+		}
+		viewerID = &id
+	}
+
+	timeline, err := service.GetApplicationTimeline(appID, viewerID)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"message": err.Error(),
@@ -152,6 +201,41 @@ func CancelApplication(c *gin.Context) {
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    timeline,
+	})
+}
+
+// CancelApplication 撤回报名
+func CancelApplication(c *gin.Context) {
+	appIDStr := c.Param("applicationId")
+	appID, err := strconv.Atoi(appIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "报名ID格式不正确",
+		})
+		return
+	}
+
+	// 撤回原因可选，请求体可以为空
+	var req model.CancelApplicationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "请求数据格式错误",
+			})
+			return
+		}
+	}
+
+	if err := service.CancelApplication(appID, req.Reason); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "取消报名成功",
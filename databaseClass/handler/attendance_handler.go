@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"volunteer-system/model"
+	"volunteer-system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GenerateCheckInCode 组织者生成签到/签退码（可渲染为二维码）
+func GenerateCheckInCode(c *gin.Context) {
+	activityID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "活动ID格式不正确",
+		})
+		return
+	}
+
+	var req model.GenerateCheckInCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	info, err := service.GenerateCheckInCode(activityID, &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if isForbidden(err) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    info,
+	})
+}
+
+// CheckIn 志愿者提交签到码签到
+func CheckIn(c *gin.Context) {
+	submitCheckInCode(c, service.CheckIn, "签到成功")
+}
+
+// CheckOut 志愿者提交签退码签退
+func CheckOut(c *gin.Context) {
+	submitCheckInCode(c, service.CheckOut, "签退成功")
+}
+
+func submitCheckInCode(c *gin.Context, submit func(activityID, userID int, code string) (*model.Attendance, error), successMessage string) {
+	activityID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "活动ID格式不正确",
+		})
+		return
+	}
+
+	var req model.SubmitCheckInCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	attendance, err := submit(activityID, req.UserID, req.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": successMessage,
+		"data":    attendance,
+	})
+}
+
+// MarkAttendance 管理员手动登记考勤
+func MarkAttendance(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("applicationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "报名ID格式不正确",
+		})
+		return
+	}
+
+	var req model.MarkAttendanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	attendance, err := service.MarkAttendance(appID, &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if isForbidden(err) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "考勤登记成功",
+		"data":    attendance,
+	})
+}
+
+// ListActivityAttendance 查看活动考勤名单
+func ListActivityAttendance(c *gin.Context) {
+	activityID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "活动ID格式不正确",
+		})
+		return
+	}
+
+	list, err := service.ListActivityAttendance(activityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    list,
+	})
+}
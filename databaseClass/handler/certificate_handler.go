@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"volunteer-system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetActivityCertificate 下载单次活动的志愿服务证明（PDF）
+func GetActivityCertificate(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("applicationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "报名ID格式不正确",
+		})
+		return
+	}
+
+	pdf, cert, err := service.GenerateActivityCertificate(appID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=certificate-%s.pdf", cert.VerificationCode))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// GetUserTranscript 下载用户的志愿服务记录（PDF）
+func GetUserTranscript(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "用户ID格式不正确",
+		})
+		return
+	}
+
+	pdf, cert, err := service.GenerateTranscript(userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=transcript-%s.pdf", cert.VerificationCode))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// VerifyCertificate 公开查验证明文件的真伪
+func VerifyCertificate(c *gin.Context) {
+	result, err := service.VerifyCertificate(c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"volunteer-system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventHeartbeatInterval 心跳间隔，防止代理因连接空闲而断开
+const eventHeartbeatInterval = 25 * time.Second
+
+// StreamUserEvents 以Server-Sent Events推送用户的实时事件：
+// 活动剩余名额变化、本人报名状态变更和新通知，管理员另外接收新报名和全部报名状态变更
+func StreamUserEvents(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "用户ID格式不正确",
+		})
+		return
+	}
+
+	sub, err := service.SubscribeEvents(userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	defer service.UnsubscribeEvents(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, "retry: 3000\n\n")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		case ev, ok := <-sub.Events():
+			if !ok {
+				// 服务关闭
+				return
+			}
+			data, err := json.Marshal(ev.Data)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+			c.Writer.Flush()
+		}
+	}
+}
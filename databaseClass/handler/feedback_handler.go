@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"volunteer-system/model"
+	"volunteer-system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SubmitActivityFeedback 志愿者为参加过的活动评分并反馈
+func SubmitActivityFeedback(c *gin.Context) {
+	activityID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "活动ID格式不正确",
+		})
+		return
+	}
+
+	var req model.SubmitFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	feedback, err := service.SubmitActivityFeedback(activityID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "评价提交成功",
+		"data":    feedback,
+	})
+}
+
+// ListActivityFeedback 查看活动的评分和反馈
+func ListActivityFeedback(c *gin.Context) {
+	activityID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "活动ID格式不正确",
+		})
+		return
+	}
+
+	feedback, err := service.ListActivityFeedback(activityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    feedback,
+	})
+}
+
+// RateVolunteer 管理员评价参加活动的志愿者
+func RateVolunteer(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("applicationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "报名ID格式不正确",
+		})
+		return
+	}
+
+	var req model.RateVolunteerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	rating, err := service.RateVolunteer(appID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "评价成功",
+		"data":    rating,
+	})
+}
+
+// GetUserVolunteerRatings 管理员查看志愿者收到的评价（handler_id 通过查询参数传入）
+func GetUserVolunteerRatings(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "用户ID格式不正确",
+		})
+		return
+	}
+	handlerID, err := strconv.Atoi(c.Query("handler_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "handler_id格式不正确",
+		})
+		return
+	}
+
+	ratings, err := service.GetUserVolunteerRatings(userID, handlerID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    ratings,
+	})
+}
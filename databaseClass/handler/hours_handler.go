@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"volunteer-system/model"
+	"volunteer-system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUserServiceHours 查询用户服务时长合计及明细
+func GetUserServiceHours(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "用户ID格式不正确",
+		})
+		return
+	}
+
+	hours, err := service.GetUserServiceHours(userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    hours,
+	})
+}
+
+// AdjustServiceHours 管理员调整用户服务时长
+func AdjustServiceHours(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "用户ID格式不正确",
+		})
+		return
+	}
+
+	var req model.AdjustServiceHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	entry, err := service.AdjustServiceHours(userID, &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if isForbidden(err) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "服务时长已调整",
+		"data":    entry,
+	})
+}
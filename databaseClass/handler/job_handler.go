@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"volunteer-system/model"
+	"volunteer-system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jobErrorStatus 非管理员返回403，任务不存在返回404，任务正在执行返回409，其余为500
+func jobErrorStatus(err error) int {
+	var codedErr *service.CodedError
+	if errors.As(err, &codedErr) {
+		switch codedErr.Code {
+		case service.ErrCodeForbidden:
+			return http.StatusForbidden
+		case service.ErrCodeJobNotFound:
+			return http.StatusNotFound
+		case service.ErrCodeJobRunning:
+			return http.StatusConflict
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+func queryHandlerID(c *gin.Context) (int, bool) {
+	handlerID, err := strconv.Atoi(c.Query("handler_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "handler_id格式不正确",
+		})
+		return 0, false
+	}
+	return handlerID, true
+}
+
+// ListJobs 管理员查看后台任务列表
+func ListJobs(c *gin.Context) {
+	handlerID, ok := queryHandlerID(c)
+	if !ok {
+		return
+	}
+
+	jobs, err := service.ListJobs(handlerID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    jobs,
+	})
+}
+
+// ListJobRuns 管理员查看任务运行历史（limit默认50）
+func ListJobRuns(c *gin.Context) {
+	handlerID, ok := queryHandlerID(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	runs, err := service.ListJobRuns(c.Param("name"), handlerID, limit)
+	if err != nil {
+		c.JSON(jobErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    runs,
+	})
+}
+
+// TriggerJob 管理员立即执行一次任务，返回本次运行记录
+func TriggerJob(c *gin.Context) {
+	var req model.JobActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	run, err := service.TriggerJob(c.Request.Context(), c.Param("name"), req.HandlerID)
+	if err != nil {
+		c.JSON(jobErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	message := "任务执行完成"
+	if run.Status == model.JobRunStatusFailed {
+		message = "任务执行失败：" + run.ErrorMessage
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": run.Status != model.JobRunStatusFailed,
+		"message": message,
+		"data":    run,
+	})
+}
+
+// PauseJob 管理员暂停任务的定时执行
+func PauseJob(c *gin.Context) {
+	setJobPaused(c, true, "任务已暂停")
+}
+
+// ResumeJob 管理员恢复任务的定时执行
+func ResumeJob(c *gin.Context) {
+	setJobPaused(c, false, "任务已恢复")
+}
+
+func setJobPaused(c *gin.Context, paused bool, message string) {
+	var req model.JobActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	if err := service.SetJobPaused(c.Param("name"), req.HandlerID, paused); err != nil {
+		c.JSON(jobErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": message,
+	})
+}
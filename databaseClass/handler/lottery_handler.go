@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"volunteer-system/model"
+	"volunteer-system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RunLotteryDraw 管理员对报名已截止的抽签活动手动发起抽签
+func RunLotteryDraw(c *gin.Context) {
+	activityID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "活动ID格式不正确",
+		})
+		return
+	}
+
+	var req model.RunLotteryDrawRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	result, err := service.RunLotteryDraw(activityID, &req.HandlerID)
+	if err != nil {
+		status := http.StatusBadRequest
+		if isForbidden(err) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "抽签完成",
+		"data":    result,
+	})
+}
+
+// GetLotteryDraw 查看活动的抽签种子和结果
+func GetLotteryDraw(c *gin.Context) {
+	activityID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "活动ID格式不正确",
+		})
+		return
+	}
+
+	result, err := service.GetLotteryDraw(activityID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
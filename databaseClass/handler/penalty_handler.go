@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"volunteer-system/model"
+	"volunteer-system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListPenalties 管理员查看报名限制（handler_id 为管理员ID，active=true 只看生效中的）
+func ListPenalties(c *gin.Context) {
+	handlerID, ok := queryHandlerID(c)
+	if !ok {
+		return
+	}
+	activeOnly := c.Query("active") == "true" || c.Query("active") == "1"
+
+	penalties, err := service.ListPenalties(handlerID, activeOnly)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if isForbidden(err) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    penalties,
+	})
+}
+
+// ListUserPenalties 查看某用户的报名限制记录
+func ListUserPenalties(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "用户ID格式不正确",
+		})
+		return
+	}
+
+	penalties, err := service.ListUserPenalties(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    penalties,
+	})
+}
+
+// LiftPenalty 管理员解除报名限制
+func LiftPenalty(c *gin.Context) {
+	penaltyID, err := strconv.Atoi(c.Param("penaltyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "限制记录ID格式不正确",
+		})
+		return
+	}
+
+	var req model.LiftPenaltyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	if err := service.LiftPenalty(penaltyID, &req); err != nil {
+		status := http.StatusBadRequest
+		if isForbidden(err) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "报名限制已解除",
+	})
+}
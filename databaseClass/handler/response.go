@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"errors"
+
+	"volunteer-system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorResponse 构造失败响应，业务错误带上错误码
+func errorResponse(err error) gin.H {
+	resp := gin.H{
+		"success": false,
+		"message": err.Error(),
+	}
+
+	var codedErr *service.CodedError
+	if errors.As(err, &codedErr) {
+		resp["code"] = codedErr.Code
+	}
+	return resp
+}
+
+// isForbidden 是否为非管理员执行管理操作的错误
+func isForbidden(err error) bool {
+	var codedErr *service.CodedError
+	return errors.As(err, &codedErr) && codedErr.Code == service.ErrCodeForbidden
+}
@@ -194,3 +194,54 @@ func GetDeptActivityInfo(c *gin.Context) {
 		"data":    info,
 	})
 }
+
+// GetUserHoursStatistics 按用户统计服务时长
+func GetUserHoursStatistics(c *gin.Context) {
+	stats, err := service.GetUserHoursStatistics()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// GetDeptHoursStatistics 按部门统计服务时长
+func GetDeptHoursStatistics(c *gin.Context) {
+	stats, err := service.GetDeptHoursStatistics()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// GetCategoryHoursStatistics 按分类统计服务时长
+func GetCategoryHoursStatistics(c *gin.Context) {
+	stats, err := service.GetCategoryHoursStatistics()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"volunteer-system/model"
+	"volunteer-system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateTeam 创建团队
+func CreateTeam(c *gin.Context) {
+	var req model.CreateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	team, err := service.CreateTeam(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "创建团队成功",
+		"data":    team,
+	})
+}
+
+// GetTeam 查看团队及成员
+func GetTeam(c *gin.Context) {
+	teamID, err := strconv.Atoi(c.Param("teamId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "团队ID格式不正确",
+		})
+		return
+	}
+
+	team, err := service.GetTeam(teamID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    team,
+	})
+}
+
+// ListUserTeams 查看用户所在的团队
+func ListUserTeams(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "用户ID格式不正确",
+		})
+		return
+	}
+
+	teams, err := service.ListUserTeams(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    teams,
+	})
+}
+
+// AddTeamMember 队长添加成员
+func AddTeamMember(c *gin.Context) {
+	teamID, err := strconv.Atoi(c.Param("teamId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "团队ID格式不正确",
+		})
+		return
+	}
+
+	var req model.TeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	if err := service.AddTeamMember(teamID, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "添加成员成功",
+	})
+}
+
+// RemoveTeamMember 队长移除成员或成员退出团队（handler_id 通过查询参数传入）
+func RemoveTeamMember(c *gin.Context) {
+	teamID, err := strconv.Atoi(c.Param("teamId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "团队ID格式不正确",
+		})
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "用户ID格式不正确",
+		})
+		return
+	}
+	handlerID, err := strconv.Atoi(c.Query("handler_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "handler_id格式不正确",
+		})
+		return
+	}
+
+	if err := service.RemoveTeamMember(teamID, userID, handlerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "移除成员成功",
+	})
+}
+
+// ApplyAsTeam 队长代表团队报名活动
+func ApplyAsTeam(c *gin.Context) {
+	activityID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "活动ID格式不正确",
+		})
+		return
+	}
+
+	var req model.TeamApplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	teamApp, err := service.ApplyAsTeam(activityID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "团队报名成功",
+		"data":    teamApp,
+	})
+}
+
+// GetTeamApplication 查看团队报名及各成员状态
+func GetTeamApplication(c *gin.Context) {
+	teamAppID, err := strconv.Atoi(c.Param("teamApplicationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "团队报名ID格式不正确",
+		})
+		return
+	}
+
+	teamApp, err := service.GetTeamApplication(teamAppID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    teamApp,
+	})
+}
+
+// ReviewTeamApplication 管理员整体审核团队报名
+func ReviewTeamApplication(c *gin.Context) {
+	teamAppID, err := strconv.Atoi(c.Param("teamApplicationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "团队报名ID格式不正确",
+		})
+		return
+	}
+
+	var req model.UpdateApplicationStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	if err := service.ReviewTeamApplication(teamAppID, req.Status, req.HandlerID, req.Comment, req.OverrideConflict); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "团队报名审核成功",
+	})
+}
+
+// CancelTeamApplication 取消团队报名，全体成员报名一并撤回
+func CancelTeamApplication(c *gin.Context) {
+	teamAppID, err := strconv.Atoi(c.Param("teamApplicationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "团队报名ID格式不正确",
+		})
+		return
+	}
+
+	var req model.CancelTeamApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	if err := service.CancelTeamApplication(teamAppID, &req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "团队报名已取消",
+	})
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"volunteer-system/model"
+	"volunteer-system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateWebhook 管理员创建Webhook订阅，响应中包含签名密钥（只返回这一次）
+func CreateWebhook(c *gin.Context) {
+	var req model.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	sub, err := service.CreateWebhook(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "创建Webhook订阅成功",
+		"data":    sub,
+	})
+}
+
+// ListWebhooks 管理员查看Webhook订阅
+func ListWebhooks(c *gin.Context) {
+	handlerID, ok := queryHandlerID(c)
+	if !ok {
+		return
+	}
+
+	subs, err := service.ListWebhooks(handlerID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    subs,
+	})
+}
+
+// DeactivateWebhook 管理员停用Webhook订阅
+func DeactivateWebhook(c *gin.Context) {
+	subscriptionID, err := strconv.Atoi(c.Param("webhookId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "订阅ID格式不正确",
+		})
+		return
+	}
+	handlerID, ok := queryHandlerID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.DeactivateWebhook(subscriptionID, handlerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhook订阅已停用",
+	})
+}
+
+// ListWebhookDeliveries 管理员查看订阅的投递记录（status可选：pending / succeeded / failed）
+func ListWebhookDeliveries(c *gin.Context) {
+	subscriptionID, err := strconv.Atoi(c.Param("webhookId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "订阅ID格式不正确",
+		})
+		return
+	}
+	handlerID, ok := queryHandlerID(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	deliveries, err := service.ListWebhookDeliveries(subscriptionID, handlerID, c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    deliveries,
+	})
+}
+
+// ReplayWebhookDelivery 管理员重放一条投递
+func ReplayWebhookDelivery(c *gin.Context) {
+	deliveryID, err := strconv.ParseInt(c.Param("deliveryId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "投递ID格式不正确",
+		})
+		return
+	}
+
+	var req model.ReplayWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	if err := service.ReplayWebhookDelivery(deliveryID, req.HandlerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "已加入重新投递",
+	})
+}
+
+// ReplayFailedWebhookDeliveries 管理员重放订阅下所有失败的投递
+func ReplayFailedWebhookDeliveries(c *gin.Context) {
+	subscriptionID, err := strconv.Atoi(c.Param("webhookId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "订阅ID格式不正确",
+		})
+		return
+	}
+
+	var req model.ReplayWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "请求数据格式错误",
+		})
+		return
+	}
+
+	replayed, err := service.ReplayFailedWebhookDeliveries(subscriptionID, req.HandlerID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("已重新投递 %d 条失败记录", replayed),
+		"data":    gin.H{"replayed": replayed},
+	})
+}
@@ -0,0 +1,167 @@
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"text/template"
+)
+
+// 邮件模板名称
+const (
+	TemplateApplicationApproved = "application_approved"
+	TemplateApplicationRejected = "application_rejected"
+	TemplateActivityReminder    = "activity_reminder"
+	TemplatePasswordReset       = "password_reset"
+)
+
+// 支持的语言，找不到对应语言的模板时使用 DefaultLocale
+const (
+	LocaleZhCN    = "zh-CN"
+	LocaleEnUS    = "en-US"
+	DefaultLocale = LocaleZhCN
+)
+
+type mailTemplate struct {
+	subject string
+	body    string
+}
+
+var templateSources = map[string]map[string]mailTemplate{
+	LocaleZhCN: {
+		TemplateApplicationApproved: {
+			subject: "报名已通过：{{.ActivityTitle}}",
+			body: `{{.Username}}，您好：
+
+您报名的活动“{{.ActivityTitle}}”已通过审核。
+活动时间：{{.ActivityTime}}
+活动地点：{{.Location}}
+{{if .Comment}}审核意见：{{.Comment}}
+{{end}}
+请准时参加。
+`,
+		},
+		TemplateApplicationRejected: {
+			subject: "报名未通过：{{.ActivityTitle}}",
+			body: `{{.Username}}，您好：
+
+很遗憾，您报名的活动“{{.ActivityTitle}}”未通过审核。
+{{if .Comment}}原因：{{.Comment}}
+{{end}}
+欢迎继续关注其他志愿活动。
+`,
+		},
+		TemplateActivityReminder: {
+			subject: "活动提醒：{{.ActivityTitle}}",
+			body: `{{.Username}}，您好：
+
+您报名的活动“{{.ActivityTitle}}”即将开始。
+活动时间：{{.ActivityTime}}
+活动地点：{{.Location}}
+
+请准时参加。
+`,
+		},
+		TemplatePasswordReset: {
+			subject: "重置密码",
+			body: `{{.Username}}，您好：
+
+我们收到了重置您账户密码的请求。请在{{.ExpiresMinutes}}分钟内打开以下链接设置新密码：
+{{.ResetURL}}
+
+如果不是您本人操作，请忽略此邮件。
+`,
+		},
+	},
+	LocaleEnUS: {
+		TemplateApplicationApproved: {
+			subject: "Application approved: {{.ActivityTitle}}",
+			body: `Hi {{.Username}},
+
+Your application for "{{.ActivityTitle}}" has been approved.
+Time: {{.ActivityTime}}
+Location: {{.Location}}
+{{if .Comment}}Comment: {{.Comment}}
+{{end}}
+See you there.
+`,
+		},
+		TemplateApplicationRejected: {
+			subject: "Application not approved: {{.ActivityTitle}}",
+			body: `Hi {{.Username}},
+
+Unfortunately your application for "{{.ActivityTitle}}" was not approved.
+{{if .Comment}}Reason: {{.Comment}}
+{{end}}
+Thank you for your interest in volunteering.
+`,
+		},
+		TemplateActivityReminder: {
+			subject: "Reminder: {{.ActivityTitle}}",
+			body: `Hi {{.Username}},
+
+"{{.ActivityTitle}}" is coming up soon.
+Time: {{.ActivityTime}}
+Location: {{.Location}}
+
+Please arrive on time.
+`,
+		},
+		TemplatePasswordReset: {
+			subject: "Reset your password",
+			body: `Hi {{.Username}},
+
+We received a request to reset your password. Open the link below within {{.ExpiresMinutes}} minutes to set a new one:
+{{.ResetURL}}
+
+If you did not request this, you can ignore this email.
+`,
+		},
+	},
+}
+
+type compiledTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+var templates = compileTemplates()
+
+func compileTemplates() map[string]map[string]compiledTemplate {
+	compiled := make(map[string]map[string]compiledTemplate)
+	for locale, sources := range templateSources {
+		compiled[locale] = make(map[string]compiledTemplate)
+		for name, src := range sources {
+			compiled[locale][name] = compiledTemplate{
+				subject: template.Must(template.New(name + ".subject").Parse(src.subject)),
+				body:    template.Must(template.New(name + ".body").Parse(src.body)),
+			}
+		}
+	}
+	return compiled
+}
+
+// IsSupportedLocale 判断是否有该语言的模板
+func IsSupportedLocale(locale string) bool {
+	_, ok := templates[locale]
+	return ok
+}
+
+// Render 按语言渲染邮件标题和正文
+func Render(locale, name string, data interface{}) (string, string, error) {
+	tmpl, ok := templates[locale][name]
+	if !ok {
+		tmpl, ok = templates[DefaultLocale][name]
+	}
+	if !ok {
+		return "", "", errors.New("邮件模板不存在：" + name)
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
@@ -0,0 +1,104 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+)
+
+type renderData struct {
+	Username       string
+	ActivityTitle  string
+	ActivityTime   string
+	Location       string
+	Comment        string
+	ResetURL       string
+	ExpiresMinutes int
+}
+
+func TestRender(t *testing.T) {
+	data := renderData{
+		Username:       "张三",
+		ActivityTitle:  "社区清洁",
+		ActivityTime:   "2024-05-01 09:00",
+		Location:       "中心广场",
+		ResetURL:       "https://example.com/reset?token=abc",
+		ExpiresMinutes: 30,
+	}
+	withComment := data
+	withComment.Comment = "请提前10分钟到场"
+
+	tests := []struct {
+		name        string
+		locale      string
+		template    string
+		data        renderData
+		wantSubject string
+		wantBody    []string
+		notInBody   []string
+	}{
+		{"中文批准", LocaleZhCN, TemplateApplicationApproved, withComment,
+			"报名已通过：社区清洁", []string{"张三，您好", "活动时间：2024-05-01 09:00", "活动地点：中心广场", "审核意见：请提前10分钟到场"}, nil},
+		{"中文批准无审核意见", LocaleZhCN, TemplateApplicationApproved, data,
+			"报名已通过：社区清洁", []string{"活动地点：中心广场"}, []string{"审核意见"}},
+		{"中文拒绝", LocaleZhCN, TemplateApplicationRejected, withComment,
+			"报名未通过：社区清洁", []string{"原因：请提前10分钟到场"}, nil},
+		{"中文提醒", LocaleZhCN, TemplateActivityReminder, data,
+			"活动提醒：社区清洁", []string{"即将开始", "活动时间：2024-05-01 09:00"}, nil},
+		{"中文密码重置", LocaleZhCN, TemplatePasswordReset, data,
+			"重置密码", []string{"30分钟内", "https://example.com/reset?token=abc"}, nil},
+		{"英文批准", LocaleEnUS, TemplateApplicationApproved, withComment,
+			"Application approved: 社区清洁", []string{"Hi 张三,", "Location: 中心广场", "Comment: 请提前10分钟到场"}, nil},
+		{"英文拒绝无原因", LocaleEnUS, TemplateApplicationRejected, data,
+			"Application not approved: 社区清洁", []string{"was not approved"}, []string{"Reason:"}},
+		{"英文提醒", LocaleEnUS, TemplateActivityReminder, data,
+			"Reminder: 社区清洁", []string{"Time: 2024-05-01 09:00"}, nil},
+		{"英文密码重置", LocaleEnUS, TemplatePasswordReset, data,
+			"Reset your password", []string{"within 30 minutes", "https://example.com/reset?token=abc"}, nil},
+		{"未知语言使用默认语言", "fr-FR", TemplateActivityReminder, data,
+			"活动提醒：社区清洁", []string{"即将开始"}, nil},
+		{"空语言使用默认语言", "", TemplatePasswordReset, data,
+			"重置密码", []string{"30分钟内"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, body, err := Render(tt.locale, tt.template, tt.data)
+			if err != nil {
+				t.Fatalf("Render(%q, %q) 出错: %v", tt.locale, tt.template, err)
+			}
+			if subject != tt.wantSubject {
+				t.Errorf("标题 = %q, want %q", subject, tt.wantSubject)
+			}
+			for _, s := range tt.wantBody {
+				if !strings.Contains(body, s) {
+					t.Errorf("正文缺少 %q：\n%s", s, body)
+				}
+			}
+			for _, s := range tt.notInBody {
+				if strings.Contains(body, s) {
+					t.Errorf("正文不应包含 %q：\n%s", s, body)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderUnknownTemplate(t *testing.T) {
+	if _, _, err := Render(LocaleZhCN, "no_such_template", nil); err == nil {
+		t.Error("不存在的模板应返回错误")
+	}
+}
+
+// 每种语言都提供全部模板，避免按用户语言发送时退回默认语言
+func TestTemplatesCoverAllLocales(t *testing.T) {
+	names := []string{TemplateApplicationApproved, TemplateApplicationRejected, TemplateActivityReminder, TemplatePasswordReset}
+	for _, locale := range []string{LocaleZhCN, LocaleEnUS} {
+		if !IsSupportedLocale(locale) {
+			t.Errorf("不支持语言 %s", locale)
+		}
+		for _, name := range names {
+			if _, ok := templates[locale][name]; !ok {
+				t.Errorf("语言 %s 缺少模板 %s", locale, name)
+			}
+		}
+	}
+}
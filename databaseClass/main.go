@@ -1,10 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"volunteer-system/config"
+	"volunteer-system/mailer"
+	"volunteer-system/realtime"
 	"volunteer-system/router"
+	"volunteer-system/scheduler"
 	"volunteer-system/service"
 
 	"github.com/gin-gonic/gin"
@@ -16,16 +26,74 @@ func main() {
 	}
 	fmt.Println("数据库连接成功")
 
-	// 启动定时任务：检查并关闭过期活动
-	go service.CloseExpiredActivities()
-	fmt.Println("已启动定时任务：每分钟检查一次过期活动")
+	config.LoadPolicy()
+	config.LoadScheduler()
+	config.LoadMail()
+	config.LoadWebhook()
+	config.LoadRealtime()
+
+	switch config.Mail.Sender {
+	case "smtp":
+		service.UseMailSender(mailer.SMTPSender{
+			Host:     config.Mail.SMTPHost,
+			Port:     config.Mail.SMTPPort,
+			Username: config.Mail.SMTPUsername,
+			Password: config.Mail.SMTPPassword,
+			Timeout:  config.Mail.SMTPTimeout,
+		})
+	case "file":
+		service.UseMailSender(mailer.FileSender{Dir: config.Mail.FileDir})
+	default:
+		service.UseMailSender(mailer.LogSender{})
+	}
+
+	// 收到中断或终止信号时取消ctx，停止定时任务并优雅关闭HTTP服务
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// 启动定时任务：发布活动、抽签、关闭报名、关闭过期活动、记录未到场、活动提醒、发送邮件和Webhook、清理实时事件
+	jobs := scheduler.New()
+	if err := service.RegisterJobs(jobs); err != nil {
+		panic("注册定时任务失败: " + err.Error())
+	}
+	sqlDB, err := config.DB.DB()
+	if err != nil {
+		panic("获取数据库连接失败: " + err.Error())
+	}
+	// 同一任务不会并发执行（包括管理员手动触发）
+	jobs.UseLocker(scheduler.NewMySQLLocker(sqlDB, config.Scheduler.LeaderLockName+":"))
+	if config.Scheduler.Enabled {
+		// 多实例部署时只由持有锁的领导者按调度执行任务
+		jobs.UseLeaderElection(scheduler.NewLeaderElector(sqlDB, config.Scheduler.LeaderLockName, config.Scheduler.LeaderRetryInterval))
+		jobs.Start(ctx)
+		fmt.Println("已启动定时任务")
+	}
+
+	// 每个实例都从共享事件表读取实时事件，推送给连接在本实例上的客户端
+	go service.RunEventRelay(ctx)
 
 	r := gin.Default()
 
 	router.SetupRoutes(r)
 
-	fmt.Println("服务器启动在端口8080")
-	if err := r.Run(":8080"); err != nil {
-		panic(err)
+	srv := &http.Server{Addr: ":8080", Handler: r}
+	// 关闭时结束实时推送的长连接，否则Shutdown会一直等待
+	srv.RegisterOnShutdown(realtime.Shutdown)
+	go func() {
+		fmt.Println("服务器启动在端口8080")
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			panic(err)
+		}
+	}()
+
+	<-ctx.Done()
+	fmt.Println("正在关闭服务器...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("关闭HTTP服务失败: %v", err)
 	}
+	jobs.Wait()
+	fmt.Println("服务器已关闭")
 }
@@ -1,6 +1,11 @@
 package model
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
 
 type Role struct {
 	RoleID   int    `json:"role_id" gorm:"column:role_id;primaryKey;autoIncrement"`
@@ -16,6 +21,11 @@ type User struct {
 	RoleID   int    `json:"role_id" gorm:"column:role_id;not null"`
 	Username string `json:"username" gorm:"column:username;not null;unique"`
 	Password string `json:"-" gorm:"column:password;not null"`
+	DeptID   *int   `json:"dept_id" gorm:"column:dept_id"`
+	// Email 接收通知邮件和密码重置邮件的地址，为空则不发邮件
+	Email string `json:"email" gorm:"column:email"`
+	// Locale 邮件语言，为空使用系统默认语言
+	Locale string `json:"locale" gorm:"column:locale"`
 }
 
 func (User) TableName() string {
@@ -51,30 +61,308 @@ type Activity struct {
 	Location     string    `json:"location" gorm:"column:location;not null"`
 	MaxPeople    int       `json:"max_people" gorm:"column:max_people;not null"`
 	Status       string    `json:"status" gorm:"column:status;default:active"`
+	// DurationMinutes 活动时长（分钟），未签到签退时按此计算服务时长
+	DurationMinutes int `json:"duration_minutes" gorm:"column:duration_minutes;not null;default:120"`
+	// EligibilityRules 报名资格规则，全部满足才允许报名
+	EligibilityRules EligibilityRules `json:"eligibility_rules" gorm:"column:eligibility_rules;type:text"`
+	// FormSchema 报名表单字段定义，为空则报名时无需填写
+	FormSchema FormSchema `json:"form_schema" gorm:"column:form_schema;type:text"`
+	// ApprovalMode 审核方式：人工审核 / 先到先得自动批准 / 满足条件自动批准
+	ApprovalMode string `json:"approval_mode" gorm:"column:approval_mode;not null;default:manual"`
+	// AutoApproveRules auto_criteria 模式下自动批准的条件，不满足的报名仍需人工审核
+	AutoApproveRules EligibilityRules `json:"auto_approve_rules" gorm:"column:auto_approve_rules;type:text"`
+	// RegistrationDeadline 报名截止时间，为空则活动开始前都可报名；抽签模式必填
+	RegistrationDeadline *time.Time `json:"registration_deadline" gorm:"column:registration_deadline"`
+	// PublishAt 定时发布时间，未到发布时间的活动处于待发布状态，用户不可见
+	PublishAt *time.Time `json:"publish_at" gorm:"column:publish_at"`
 }
 
 func (Activity) TableName() string {
 	return "Activity"
 }
 
+// 活动状态
+const (
+	ActivityStatusScheduled          = "scheduled"           // 待发布
+	ActivityStatusActive             = "active"              // 报名中
+	ActivityStatusRegistrationClosed = "registration_closed" // 报名已关闭（满员或已截止）
+	ActivityStatusExpired            = "expired"             // 已过期
+)
+
+// 活动审核方式
+const (
+	ApprovalModeManual        = "manual"          // 人工审核
+	ApprovalModeAutoUntilFull = "auto_until_full" // 名额未满即自动批准（先到先得）
+	ApprovalModeAutoCriteria  = "auto_criteria"   // 满足自动批准条件的直接批准
+	ApprovalModeLottery       = "lottery"         // 报名截止后抽签决定批准和候补
+)
+
+// 报名资格规则类型
+const (
+	RuleTypeDept         = "dept"          // 指定部门成员
+	RuleTypeMinCompleted = "min_completed" // 至少完成N次活动
+	RuleTypeTraining     = "training"      // 完成指定培训
+)
+
+// EligibilityRule 单条报名资格规则
+type EligibilityRule struct {
+	Type         string `json:"type"`
+	DeptIDs      []int  `json:"dept_ids,omitempty"`
+	MinCompleted int    `json:"min_completed,omitempty"`
+	Training     string `json:"training,omitempty"`
+}
+
+// EligibilityRules 以JSON形式存储在Activity.eligibility_rules列中
+type EligibilityRules []EligibilityRule
+
+func (r EligibilityRules) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (r *EligibilityRules) Scan(value interface{}) error {
+	return scanJSON(value, r)
+}
+
+// scanJSON 将数据库中的JSON文本列解析到dest
+func scanJSON(value interface{}, dest interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("不支持的JSON列类型")
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// 报名表单字段类型
+const (
+	FormFieldText         = "text"
+	FormFieldSingleChoice = "single_choice"
+	FormFieldMultiChoice  = "multi_choice"
+	FormFieldNumber       = "number"
+	FormFieldDate         = "date"
+)
+
+// FormField 报名表单中的一个问题
+type FormField struct {
+	Key      string   `json:"key"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"`
+}
+
+// FormSchema 以JSON形式存储在Activity.form_schema列中
+type FormSchema []FormField
+
+func (f FormSchema) Value() (driver.Value, error) {
+	if len(f) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (f *FormSchema) Scan(value interface{}) error {
+	return scanJSON(value, f)
+}
+
+// FormAnswers 报名表单的回答，键为FormField.Key，存储在Application.answers列中
+type FormAnswers map[string]interface{}
+
+func (a FormAnswers) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (a *FormAnswers) Scan(value interface{}) error {
+	return scanJSON(value, a)
+}
+
+type UserTraining struct {
+	TrainingID   int       `json:"training_id" gorm:"column:training_id;primaryKey;autoIncrement"`
+	UserID       int       `json:"user_id" gorm:"column:user_id;not null"`
+	TrainingName string    `json:"training_name" gorm:"column:training_name;not null"`
+	CompletedAt  time.Time `json:"completed_at" gorm:"column:completed_at;not null"`
+}
+
+func (UserTraining) TableName() string {
+	return "UserTraining"
+}
+
 type Application struct {
-	ApplicationID int       `json:"application_id" gorm:"column:application_id;primaryKey;autoIncrement"`
-	UserID        int       `json:"user_id" gorm:"column:user_id;not null"`
-	ActivityID    int       `json:"activity_id" gorm:"column:activity_id;not null"`
-	ApplyTime     time.Time `json:"apply_time" gorm:"column:apply_time;not null"`
-	CurrentStatus string    `json:"current_status" gorm:"column:current_status;not null;default:pending"`
+	ApplicationID    int         `json:"application_id" gorm:"column:application_id;primaryKey;autoIncrement"`
+	UserID           int         `json:"user_id" gorm:"column:user_id;not null"`
+	ActivityID       int         `json:"activity_id" gorm:"column:activity_id;not null"`
+	ApplyTime        time.Time   `json:"apply_time" gorm:"column:apply_time;not null"`
+	CurrentStatus    string      `json:"current_status" gorm:"column:current_status;not null;default:pending"`
+	Answers          FormAnswers `json:"answers" gorm:"column:answers;type:text"`
+	AttendanceStatus string      `json:"attendance_status" gorm:"column:attendance_status;not null;default:''"`
+	// TeamApplicationID 以团队身份报名时关联的团队报名
+	TeamApplicationID *int `json:"team_application_id" gorm:"column:team_application_id"`
 }
 
 func (Application) TableName() string {
 	return "Application"
 }
 
+// 报名状态
+const (
+	AppStatusPending              = "pending"                // 待审核
+	AppStatusApproved             = "approved"               // 已批准
+	AppStatusRejected             = "rejected"               // 已拒绝
+	AppStatusWithdrawn            = "withdrawn"              // 用户已撤回
+	AppStatusCancelledByOrganizer = "cancelled_by_organizer" // 组织者已取消
+	AppStatusWaitlisted           = "waitlisted"             // 候补
+	AppStatusAttended             = "attended"               // 已参加
+	AppStatusNoShow               = "no_show"                // 未到场
+	AppStatusExpired              = "expired"                // 活动过期时仍未审核
+)
+
+// 考勤状态
+const (
+	AttendanceNone       = ""            // 未记录
+	AttendanceCheckedIn  = "checked_in"  // 已签到
+	AttendanceCheckedOut = "checked_out" // 已签退
+	AttendanceConfirmed  = "confirmed"   // 管理员确认到场（无签到时间）
+	AttendanceAbsent     = "absent"      // 缺席
+)
+
+// 签到码用途
+const (
+	CheckInPurposeIn  = "check_in"
+	CheckInPurposeOut = "check_out"
+)
+
+// CheckInCode 组织者生成的签到/签退码，新码生成后旧码立即失效
+type CheckInCode struct {
+	CodeID     int       `json:"code_id" gorm:"column:code_id;primaryKey;autoIncrement"`
+	ActivityID int       `json:"activity_id" gorm:"column:activity_id;not null"`
+	Code       string    `json:"code" gorm:"column:code;not null"`
+	Purpose    string    `json:"purpose" gorm:"column:purpose;not null"`
+	CreatedBy  int       `json:"created_by" gorm:"column:created_by;not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"column:created_at;not null"`
+	ExpiresAt  time.Time `json:"expires_at" gorm:"column:expires_at;not null"`
+}
+
+func (CheckInCode) TableName() string {
+	return "CheckInCode"
+}
+
+// Attendance 报名的签到签退记录，每条报名最多一条
+type Attendance struct {
+	AttendanceID  int        `json:"attendance_id" gorm:"column:attendance_id;primaryKey;autoIncrement"`
+	ApplicationID int        `json:"application_id" gorm:"column:application_id;not null;unique"`
+	ActivityID    int        `json:"activity_id" gorm:"column:activity_id;not null"`
+	UserID        int        `json:"user_id" gorm:"column:user_id;not null"`
+	CheckInTime   *time.Time `json:"check_in_time" gorm:"column:check_in_time"`
+	CheckOutTime  *time.Time `json:"check_out_time" gorm:"column:check_out_time"`
+	CheckInCodeID *int       `json:"check_in_code_id" gorm:"column:check_in_code_id"`
+	Method        string     `json:"method" gorm:"column:method;not null"` // code / manual
+	MarkedBy      *int       `json:"marked_by" gorm:"column:marked_by"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"column:updated_at;not null"`
+}
+
+func (Attendance) TableName() string {
+	return "Attendance"
+}
+
+// 服务时长记录来源
+const (
+	HoursSourceAttendance = "attendance" // 按签到签退时间计算
+	HoursSourceDuration   = "duration"   // 确认到场，按活动时长计算
+	HoursSourceAdjustment = "adjustment" // 管理员调整
+)
+
+// ServiceHoursEntry 志愿服务时长台账，每条报名最多一条计算记录，调整记录另行追加
+type ServiceHoursEntry struct {
+	EntryID       int       `json:"entry_id" gorm:"column:entry_id;primaryKey;autoIncrement"`
+	UserID        int       `json:"user_id" gorm:"column:user_id;not null"`
+	ApplicationID *int      `json:"application_id" gorm:"column:application_id"`
+	ActivityID    *int      `json:"activity_id" gorm:"column:activity_id"`
+	Hours         float64   `json:"hours" gorm:"column:hours;not null"`
+	Source        string    `json:"source" gorm:"column:source;not null"`
+	Reason        string    `json:"reason" gorm:"column:reason"`
+	CreatedBy     *int      `json:"created_by" gorm:"column:created_by"`
+	CreatedAt     time.Time `json:"created_at" gorm:"column:created_at;not null"`
+}
+
+func (ServiceHoursEntry) TableName() string {
+	return "ServiceHoursEntry"
+}
+
+// 证明文件类型
+const (
+	CertificateTypeActivity   = "certificate" // 单次活动服务证明
+	CertificateTypeTranscript = "transcript"  // 服务记录成绩单
+)
+
+// Certificate 已签发的证明文件，凭验证码可公开查验
+type Certificate struct {
+	CertificateID    int       `json:"certificate_id" gorm:"column:certificate_id;primaryKey;autoIncrement"`
+	VerificationCode string    `json:"verification_code" gorm:"column:verification_code;not null;unique"`
+	Type             string    `json:"type" gorm:"column:type;not null"`
+	UserID           int       `json:"user_id" gorm:"column:user_id;not null"`
+	ApplicationID    *int      `json:"application_id" gorm:"column:application_id"`
+	ActivityCount    int       `json:"activity_count" gorm:"column:activity_count;not null"`
+	TotalHours       float64   `json:"total_hours" gorm:"column:total_hours;not null"`
+	IssuedAt         time.Time `json:"issued_at" gorm:"column:issued_at;not null"`
+}
+
+func (Certificate) TableName() string {
+	return "Certificate"
+}
+
+// ParticipationPenalty 多次未到场导致的报名限制
+type ParticipationPenalty struct {
+	PenaltyID   int        `json:"penalty_id" gorm:"column:penalty_id;primaryKey;autoIncrement"`
+	UserID      int        `json:"user_id" gorm:"column:user_id;not null"`
+	NoShowCount int        `json:"no_show_count" gorm:"column:no_show_count;not null"`
+	Reason      string     `json:"reason" gorm:"column:reason;not null"`
+	StartTime   time.Time  `json:"start_time" gorm:"column:start_time;not null"`
+	EndTime     time.Time  `json:"end_time" gorm:"column:end_time;not null"`
+	LiftedAt    *time.Time `json:"lifted_at" gorm:"column:lifted_at"`
+	LiftedBy    *int       `json:"lifted_by" gorm:"column:lifted_by"`
+	LiftReason  string     `json:"lift_reason" gorm:"column:lift_reason"`
+}
+
+func (ParticipationPenalty) TableName() string {
+	return "ParticipationPenalty"
+}
+
 type ApplicationStatusLog struct {
 	LogID         int       `json:"log_id" gorm:"column:log_id;primaryKey;autoIncrement"`
 	ApplicationID int       `json:"application_id" gorm:"column:application_id;not null"`
 	HandlerID     *int      `json:"handler_id" gorm:"column:handler_id"`
 	LogStatus     string    `json:"log_status" gorm:"column:log_status;not null"`
 	HandleTime    time.Time `json:"handle_time" gorm:"column:handle_time;not null"`
+	Comment       string    `json:"comment" gorm:"column:comment"`
+	// InternalNote 系统说明（如管理员忽略时间冲突），只在管理端显示，不通知报名者
+	InternalNote string `json:"internal_note" gorm:"column:internal_note"`
 }
 
 func (ApplicationStatusLog) TableName() string {
@@ -86,6 +374,8 @@ type RegisterRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 	RoleName string `json:"role_name"`
+	DeptID   *int   `json:"dept_id"`
+	Email    string `json:"email"`
 }
 
 type LoginRequest struct {
@@ -109,6 +399,18 @@ type CreateActivityRequest struct {
 	ActivityTime string `json:"activity_time" binding:"required"`
 	Location     string `json:"location" binding:"required"`
 	MaxPeople    int    `json:"max_people" binding:"required"`
+	// DurationMinutes 活动时长（分钟），不填默认120
+	DurationMinutes int `json:"duration_minutes"`
+
+	EligibilityRules EligibilityRules `json:"eligibility_rules"`
+	FormSchema       FormSchema       `json:"form_schema"`
+	// ApprovalMode 审核方式，不填默认人工审核
+	ApprovalMode     string           `json:"approval_mode"`
+	AutoApproveRules EligibilityRules `json:"auto_approve_rules"`
+	// RegistrationDeadline 报名截止时间（YYYY-MM-DD HH:MM），抽签模式必填
+	RegistrationDeadline string `json:"registration_deadline"`
+	// PublishAt 定时发布时间（YYYY-MM-DD HH:MM），为空则立即发布
+	PublishAt string `json:"publish_at"`
 }
 
 type UpdateActivityRequest struct {
@@ -120,31 +422,659 @@ type UpdateActivityRequest struct {
 	ActivityTime string `json:"activity_time" binding:"required"`
 	Location     string `json:"location" binding:"required"`
 	MaxPeople    int    `json:"max_people" binding:"required"`
+	// DurationMinutes 活动时长（分钟），不填默认120
+	DurationMinutes int `json:"duration_minutes"`
+
+	EligibilityRules EligibilityRules `json:"eligibility_rules"`
+	FormSchema       FormSchema       `json:"form_schema"`
+	// ApprovalMode 审核方式，不填默认人工审核
+	ApprovalMode     string           `json:"approval_mode"`
+	AutoApproveRules EligibilityRules `json:"auto_approve_rules"`
+	// RegistrationDeadline 报名截止时间（YYYY-MM-DD HH:MM），抽签模式必填
+	RegistrationDeadline string `json:"registration_deadline"`
+	// PublishAt 定时发布时间（YYYY-MM-DD HH:MM），为空则立即发布
+	PublishAt string `json:"publish_at"`
 }
 
 type ApplyActivityRequest struct {
-	UserID int `json:"user_id" binding:"required"`
+	UserID  int         `json:"user_id" binding:"required"`
+	Answers FormAnswers `json:"answers"`
+	// OverrideConflict 管理员代为报名时可忽略时间冲突，须同时填写管理员的handler_id
+	OverrideConflict bool `json:"override_conflict"`
+	HandlerID        *int `json:"handler_id"`
 }
 
 type UpdateApplicationStatusRequest struct {
 	Status    string `json:"status" binding:"required"`
 	HandlerID int    `json:"handler_id" binding:"required"`
+	Comment   string `json:"comment"` // 审核意见，拒绝时必填（拒绝原因）
+	// OverrideConflict 批准时忽略与该用户其他已批准活动的时间冲突
+	OverrideConflict bool `json:"override_conflict"`
+}
+
+// BulkReviewRequest 批量审核：指定报名ID列表，或指定活动并按报名顺序批准前 first_n 条待审核报名
+type BulkReviewRequest struct {
+	Status         string `json:"status" binding:"required"` // approved / rejected
+	HandlerID      int    `json:"handler_id" binding:"required"`
+	Comment        string `json:"comment"`
+	ApplicationIDs []int  `json:"application_ids"`
+	ActivityID     *int   `json:"activity_id"`
+	FirstN         int    `json:"first_n"`
+	// OverrideConflict 批准时忽略时间冲突
+	OverrideConflict bool `json:"override_conflict"`
+}
+
+// BulkReviewItemResult 批量审核中单条报名的处理结果
+type BulkReviewItemResult struct {
+	ApplicationID int    `json:"application_id"`
+	Success       bool   `json:"success"`
+	Code          string `json:"code,omitempty"`
+	Message       string `json:"message,omitempty"`
+}
+
+type BulkReviewResult struct {
+	Total     int                    `json:"total"`
+	Succeeded int                    `json:"succeeded"`
+	Failed    int                    `json:"failed"`
+	Items     []BulkReviewItemResult `json:"items"`
+}
+
+type GenerateCheckInCodeRequest struct {
+	HandlerID int    `json:"handler_id" binding:"required"`
+	Purpose   string `json:"purpose"` // check_in（默认）/ check_out
+}
+
+type SubmitCheckInCodeRequest struct {
+	UserID int    `json:"user_id" binding:"required"`
+	Code   string `json:"code" binding:"required"` // 签到码或二维码内容
+}
+
+type MarkAttendanceRequest struct {
+	HandlerID    int    `json:"handler_id" binding:"required"`
+	Status       string `json:"status" binding:"required"` // present / absent
+	CheckInTime  string `json:"check_in_time"`
+	CheckOutTime string `json:"check_out_time"`
+}
+
+// CheckInCodeInfo 返回给组织者的签到码，QRPayload可直接生成二维码
+type CheckInCodeInfo struct {
+	Code      string    `json:"code"`
+	Purpose   string    `json:"purpose"`
+	ExpiresAt time.Time `json:"expires_at"`
+	QRPayload string    `json:"qr_payload"`
+}
+
+// ActivityAttendanceInfo 活动考勤名单
+type ActivityAttendanceInfo struct {
+	ApplicationID    int        `json:"application_id"`
+	UserID           int        `json:"user_id"`
+	Username         string     `json:"username"`
+	CurrentStatus    string     `json:"current_status"`
+	AttendanceStatus string     `json:"attendance_status"`
+	CheckInTime      *time.Time `json:"check_in_time"`
+	CheckOutTime     *time.Time `json:"check_out_time"`
+	Method           string     `json:"method"`
+}
+
+type AdjustServiceHoursRequest struct {
+	HandlerID     int     `json:"handler_id" binding:"required"`
+	Hours         float64 `json:"hours" binding:"required"` // 正数增加，负数扣减
+	Reason        string  `json:"reason" binding:"required"`
+	ApplicationID *int    `json:"application_id"`
+}
+
+// ServiceHoursEntryInfo 服务时长台账明细（含活动标题）
+type ServiceHoursEntryInfo struct {
+	EntryID       int       `json:"entry_id"`
+	ApplicationID *int      `json:"application_id"`
+	ActivityID    *int      `json:"activity_id"`
+	ActivityTitle string    `json:"activity_title"`
+	Hours         float64   `json:"hours"`
+	Source        string    `json:"source"`
+	Reason        string    `json:"reason"`
+	CreatedBy     *int      `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// UserServiceHours 用户服务时长汇总
+type UserServiceHours struct {
+	UserID     int                     `json:"user_id"`
+	TotalHours float64                 `json:"total_hours"`
+	Entries    []ServiceHoursEntryInfo `json:"entries"`
+}
+
+// CertificateVerification 公开查验证明文件的结果
+type CertificateVerification struct {
+	Valid            bool      `json:"valid"`
+	VerificationCode string    `json:"verification_code"`
+	Type             string    `json:"type"`
+	Username         string    `json:"username"`
+	ActivityTitle    string    `json:"activity_title,omitempty"`
+	ActivityCount    int       `json:"activity_count"`
+	TotalHours       float64   `json:"total_hours"`
+	IssuedAt         time.Time `json:"issued_at"`
+}
+
+type LiftPenaltyRequest struct {
+	HandlerID int    `json:"handler_id" binding:"required"`
+	Reason    string `json:"reason"`
+}
+
+// PenaltyInfo 报名限制记录（含用户名）
+type PenaltyInfo struct {
+	ParticipationPenalty
+	Username string `json:"username"`
+	Active   bool   `json:"active"`
+}
+
+// LotteryDraw 抽签记录，每个活动只抽一次；保存随机种子便于复核
+type LotteryDraw struct {
+	DrawID          int       `json:"draw_id" gorm:"column:draw_id;primaryKey;autoIncrement"`
+	ActivityID      int       `json:"activity_id" gorm:"column:activity_id;not null;uniqueIndex"`
+	Seed            int64     `json:"seed" gorm:"column:seed;not null"`
+	ApplicantCount  int       `json:"applicant_count" gorm:"column:applicant_count;not null"`
+	ApprovedCount   int       `json:"approved_count" gorm:"column:approved_count;not null"`
+	WaitlistedCount int       `json:"waitlisted_count" gorm:"column:waitlisted_count;not null"`
+	DrawnBy         *int      `json:"drawn_by" gorm:"column:drawn_by"`
+	DrawnAt         time.Time `json:"drawn_at" gorm:"column:drawn_at;not null"`
+}
+
+func (LotteryDraw) TableName() string {
+	return "LotteryDraw"
+}
+
+// LotteryDrawEntry 抽签结果明细，position为抽签后的顺位（从1开始）
+type LotteryDrawEntry struct {
+	EntryID       int    `json:"entry_id" gorm:"column:entry_id;primaryKey;autoIncrement"`
+	DrawID        int    `json:"draw_id" gorm:"column:draw_id;not null"`
+	ApplicationID int    `json:"application_id" gorm:"column:application_id;not null"`
+	UserID        int    `json:"user_id" gorm:"column:user_id;not null"`
+	Position      int    `json:"position" gorm:"column:position;not null"`
+	Result        string `json:"result" gorm:"column:result;not null"`
+}
+
+func (LotteryDrawEntry) TableName() string {
+	return "LotteryDrawEntry"
+}
+
+type RunLotteryDrawRequest struct {
+	HandlerID int `json:"handler_id" binding:"required"`
+}
+
+// LotteryEntryInfo 抽签结果明细（含用户名）
+type LotteryEntryInfo struct {
+	LotteryDrawEntry
+	Username string `json:"username"`
+}
+
+// LotteryDrawResult 抽签记录及结果，verified表示按种子重新抽签与保存的结果一致
+type LotteryDrawResult struct {
+	LotteryDraw
+	Entries  []LotteryEntryInfo `json:"entries"`
+	Verified bool               `json:"verified"`
+}
+
+// Team 志愿团队，队长也是成员
+type Team struct {
+	TeamID    int       `json:"team_id" gorm:"column:team_id;primaryKey;autoIncrement"`
+	Name      string    `json:"name" gorm:"column:name;not null"`
+	LeaderID  int       `json:"leader_id" gorm:"column:leader_id;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;not null"`
+}
+
+func (Team) TableName() string {
+	return "Team"
+}
+
+type TeamMember struct {
+	TeamID   int       `json:"team_id" gorm:"column:team_id;primaryKey"`
+	UserID   int       `json:"user_id" gorm:"column:user_id;primaryKey"`
+	JoinedAt time.Time `json:"joined_at" gorm:"column:joined_at;not null"`
+}
+
+func (TeamMember) TableName() string {
+	return "TeamMember"
+}
+
+// TeamApplication 团队报名，每个成员各有一条关联的报名记录
+type TeamApplication struct {
+	TeamApplicationID int       `json:"team_application_id" gorm:"column:team_application_id;primaryKey;autoIncrement"`
+	TeamID            int       `json:"team_id" gorm:"column:team_id;not null"`
+	ActivityID        int       `json:"activity_id" gorm:"column:activity_id;not null"`
+	ApplicantID       int       `json:"applicant_id" gorm:"column:applicant_id;not null"`
+	ApplyTime         time.Time `json:"apply_time" gorm:"column:apply_time;not null"`
+}
+
+func (TeamApplication) TableName() string {
+	return "TeamApplication"
+}
+
+type CreateTeamRequest struct {
+	Name      string `json:"name" binding:"required"`
+	LeaderID  int    `json:"leader_id" binding:"required"`
+	MemberIDs []int  `json:"member_ids"`
+}
+
+// TeamMemberRequest 队长添加成员
+type TeamMemberRequest struct {
+	UserID    int `json:"user_id" binding:"required"`
+	HandlerID int `json:"handler_id" binding:"required"`
+}
+
+// TeamApplyRequest 队长代表团队报名；member_answers按成员填写表单，未填写的成员使用answers
+type TeamApplyRequest struct {
+	TeamID        int                 `json:"team_id" binding:"required"`
+	UserID        int                 `json:"user_id" binding:"required"`
+	Answers       FormAnswers         `json:"answers"`
+	MemberAnswers map[int]FormAnswers `json:"member_answers"`
+}
+
+type CancelTeamApplicationRequest struct {
+	UserID int    `json:"user_id" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+type TeamMemberInfo struct {
+	UserID   int       `json:"user_id"`
+	Username string    `json:"username"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type TeamInfo struct {
+	Team
+	LeaderName string           `json:"leader_name"`
+	Members    []TeamMemberInfo `json:"members"`
+}
+
+// TeamApplicationInfo 团队报名及各成员的报名状态
+type TeamApplicationInfo struct {
+	TeamApplication
+	TeamName      string                        `json:"team_name"`
+	ActivityTitle string                        `json:"activity_title"`
+	Applications  []ActivityApplicationWithUser `json:"applications" gorm:"-"`
+}
+
+// ActivityFeedback 志愿者对已参加活动的评分和反馈，每条报名一条，可修改
+type ActivityFeedback struct {
+	FeedbackID    int       `json:"feedback_id" gorm:"column:feedback_id;primaryKey;autoIncrement"`
+	ApplicationID int       `json:"application_id" gorm:"column:application_id;not null"`
+	ActivityID    int       `json:"activity_id" gorm:"column:activity_id;not null"`
+	UserID        int       `json:"user_id" gorm:"column:user_id;not null"`
+	Rating        int       `json:"rating" gorm:"column:rating;not null"`
+	Comment       string    `json:"comment" gorm:"column:comment;not null"`
+	CreatedAt     time.Time `json:"created_at" gorm:"column:created_at;not null"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"column:updated_at;not null"`
+}
+
+func (ActivityFeedback) TableName() string {
+	return "ActivityFeedback"
+}
+
+// VolunteerRating 组织者对志愿者的评价，仅管理员可见
+type VolunteerRating struct {
+	RatingID      int       `json:"rating_id" gorm:"column:rating_id;primaryKey;autoIncrement"`
+	ApplicationID int       `json:"application_id" gorm:"column:application_id;not null"`
+	ActivityID    int       `json:"activity_id" gorm:"column:activity_id;not null"`
+	UserID        int       `json:"user_id" gorm:"column:user_id;not null"`
+	RaterID       int       `json:"rater_id" gorm:"column:rater_id;not null"`
+	Rating        int       `json:"rating" gorm:"column:rating;not null"`
+	Comment       string    `json:"comment" gorm:"column:comment;not null"`
+	CreatedAt     time.Time `json:"created_at" gorm:"column:created_at;not null"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"column:updated_at;not null"`
+}
+
+func (VolunteerRating) TableName() string {
+	return "VolunteerRating"
+}
+
+type SubmitFeedbackRequest struct {
+	UserID  int    `json:"user_id" binding:"required"`
+	Rating  int    `json:"rating" binding:"required"` // 1-5
+	Comment string `json:"comment"`
+}
+
+type RateVolunteerRequest struct {
+	HandlerID int    `json:"handler_id" binding:"required"`
+	Rating    int    `json:"rating" binding:"required"` // 1-5
+	Comment   string `json:"comment"`
+}
+
+// ActivityFeedbackInfo 活动反馈（含用户名）
+type ActivityFeedbackInfo struct {
+	ActivityFeedback
+	Username string `json:"username"`
+}
+
+// VolunteerRatingInfo 志愿者评价（含活动名称和评价人）
+type VolunteerRatingInfo struct {
+	VolunteerRating
+	ActivityTitle string `json:"activity_title"`
+	RaterName     string `json:"rater_name"`
+}
+
+type UserVolunteerRatings struct {
+	UserID      int                   `json:"user_id"`
+	AvgRating   float64               `json:"avg_rating"`
+	RatingCount int                   `json:"rating_count"`
+	Ratings     []VolunteerRatingInfo `json:"ratings"`
+}
+
+type CancelApplicationRequest struct {
+	Reason string `json:"reason"`
 }
 
 type ActivityApplicationWithUser struct {
-	ApplicationID int       `json:"application_id"`
-	UserID        int       `json:"user_id"`
-	Username      string    `json:"username"`
-	ApplyTime     time.Time `json:"apply_time"`
-	CurrentStatus string    `json:"current_status"`
+	ApplicationID    int         `json:"application_id"`
+	UserID           int         `json:"user_id"`
+	Username         string      `json:"username"`
+	ApplyTime        time.Time   `json:"apply_time"`
+	CurrentStatus    string      `json:"current_status"`
+	Answers          FormAnswers `json:"answers"`
+	AttendanceStatus string      `json:"attendance_status"`
+	// TeamApplicationID 团队报名ID，个人报名为空
+	TeamApplicationID *int `json:"team_application_id"`
 }
 
 type UserApplicationInfo struct {
-	ApplicationID int       `json:"application_id"`
-	ActivityID    int       `json:"activity_id"`
-	Title         string    `json:"title"`
-	ActivityTime  time.Time `json:"activity_time"`
-	Location      string    `json:"location"`
-	CurrentStatus string    `json:"current_status"`
-	ApplyTime     time.Time `json:"apply_time"`
+	ApplicationID    int       `json:"application_id"`
+	ActivityID       int       `json:"activity_id"`
+	Title            string    `json:"title"`
+	ActivityTime     time.Time `json:"activity_time"`
+	Location         string    `json:"location"`
+	CurrentStatus    string    `json:"current_status"`
+	ApplyTime        time.Time `json:"apply_time"`
+	StatusComment    string    `json:"status_comment"`
+	AttendanceStatus string    `json:"attendance_status"`
+}
+
+// ApplicationTimelineEntry 报名状态变更历史（含处理人用户名）
+type ApplicationTimelineEntry struct {
+	LogID        int       `json:"log_id"`
+	LogStatus    string    `json:"log_status"`
+	HandlerID    *int      `json:"handler_id"`
+	HandlerName  string    `json:"handler_name"`
+	Comment      string    `json:"comment"`
+	InternalNote string    `json:"internal_note"`
+	HandleTime   time.Time `json:"handle_time"`
+}
+
+type UpdateUserDeptRequest struct {
+	DeptID *int `json:"dept_id"`
+}
+
+// UpdateUserContactRequest 设置邮箱和邮件语言（zh-CN / en-US）
+type UpdateUserContactRequest struct {
+	Email  string `json:"email"`
+	Locale string `json:"locale"`
+}
+
+type PasswordResetRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type AddUserTrainingRequest struct {
+	TrainingName string `json:"training_name" binding:"required"`
+	CompletedAt  string `json:"completed_at"`
+}
+
+// 后台任务运行状态
+const (
+	JobRunStatusRunning   = "running"
+	JobRunStatusSucceeded = "succeeded"
+	JobRunStatusFailed    = "failed"
+)
+
+// JobRun 后台任务的一次运行记录
+type JobRun struct {
+	RunID        int64      `json:"run_id" gorm:"column:run_id;primaryKey;autoIncrement"`
+	JobName      string     `json:"job_name" gorm:"column:job_name;not null"`
+	Trigger      string     `json:"trigger" gorm:"column:trigger_type;not null"`
+	TriggeredBy  *int       `json:"triggered_by" gorm:"column:triggered_by"`
+	Status       string     `json:"status" gorm:"column:status;not null"`
+	StartedAt    time.Time  `json:"started_at" gorm:"column:started_at;not null"`
+	FinishedAt   *time.Time `json:"finished_at" gorm:"column:finished_at"`
+	AffectedRows int64      `json:"affected_rows" gorm:"column:affected_rows;not null"`
+	ErrorMessage string     `json:"error_message" gorm:"column:error_message"`
+}
+
+func (JobRun) TableName() string {
+	return "JobRun"
+}
+
+// JobState 后台任务的暂停状态，所有实例共享
+type JobState struct {
+	JobName   string    `json:"job_name" gorm:"column:job_name;primaryKey"`
+	Paused    bool      `json:"paused" gorm:"column:paused;not null"`
+	UpdatedBy *int      `json:"updated_by" gorm:"column:updated_by"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at;not null"`
+}
+
+func (JobState) TableName() string {
+	return "JobState"
+}
+
+// JobInfo 后台任务概况
+type JobInfo struct {
+	Name            string     `json:"name"`
+	IntervalSeconds int64      `json:"interval_seconds"`
+	Paused          bool       `json:"paused"`
+	LastRun         *JobRun    `json:"last_run"`
+	LastSucceededAt *time.Time `json:"last_succeeded_at"`
+}
+
+type JobActionRequest struct {
+	HandlerID int `json:"handler_id" binding:"required"`
+}
+
+// 提醒类型
+const (
+	ReminderTypeVolunteer = "volunteer" // 提醒已批准的志愿者
+	ReminderTypeRoster    = "roster"    // 向组织者发送名单汇总
+)
+
+// ReminderLog 已发送的活动提醒，唯一键保证同一提醒只发送一次
+type ReminderLog struct {
+	ReminderID    int       `json:"reminder_id" gorm:"column:reminder_id;primaryKey;autoIncrement"`
+	ReminderType  string    `json:"reminder_type" gorm:"column:reminder_type;not null"`
+	ActivityID    int       `json:"activity_id" gorm:"column:activity_id;not null"`
+	UserID        int       `json:"user_id" gorm:"column:user_id;not null"`
+	ApplicationID *int      `json:"application_id" gorm:"column:application_id"`
+	OffsetMinutes int       `json:"offset_minutes" gorm:"column:offset_minutes;not null"`
+	SentAt        time.Time `json:"sent_at" gorm:"column:sent_at;not null"`
+}
+
+func (ReminderLog) TableName() string {
+	return "ReminderLog"
+}
+
+// Notification 站内通知，可关联活动或报名
+type Notification struct {
+	NotificationID int        `json:"notification_id" gorm:"column:notification_id;primaryKey;autoIncrement"`
+	UserID         int        `json:"user_id" gorm:"column:user_id;not null"`
+	Title          string     `json:"title" gorm:"column:title;not null"`
+	Content        string     `json:"content" gorm:"column:content;type:text"`
+	ActivityID     *int       `json:"activity_id" gorm:"column:activity_id"`
+	ApplicationID  *int       `json:"application_id" gorm:"column:application_id"`
+	IsRead         bool       `json:"is_read" gorm:"column:is_read;not null"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"column:created_at;not null"`
+	ReadAt         *time.Time `json:"read_at" gorm:"column:read_at"`
+}
+
+func (Notification) TableName() string {
+	return "Notification"
+}
+
+// 邮件发送状态
+const (
+	EmailStatusPending = "pending" // 待发送（含等待重试）
+	EmailStatusSent    = "sent"    // 已发送
+	EmailStatusFailed  = "failed"  // 超过最大尝试次数
+)
+
+// EmailOutbox 待发送邮件，由业务操作在同一事务中写入，后台任务负责投递和重试
+type EmailOutbox struct {
+	EmailID       int64      `json:"email_id" gorm:"column:email_id;primaryKey;autoIncrement"`
+	UserID        *int       `json:"user_id" gorm:"column:user_id"`
+	ToAddress     string     `json:"to_address" gorm:"column:to_address;not null"`
+	Template      string     `json:"template" gorm:"column:template;not null"`
+	Subject       string     `json:"subject" gorm:"column:subject;not null"`
+	Body          string     `json:"body" gorm:"column:body;type:text"`
+	Status        string     `json:"status" gorm:"column:status;not null"`
+	Attempts      int        `json:"attempts" gorm:"column:attempts;not null"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"column:next_attempt_at;not null"`
+	LastError     string     `json:"last_error" gorm:"column:last_error"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"column:created_at;not null"`
+	SentAt        *time.Time `json:"sent_at" gorm:"column:sent_at"`
+}
+
+func (EmailOutbox) TableName() string {
+	return "EmailOutbox"
+}
+
+// PasswordResetToken 密码重置令牌，只保存令牌的SHA-256摘要
+type PasswordResetToken struct {
+	TokenID   int        `json:"token_id" gorm:"column:token_id;primaryKey;autoIncrement"`
+	UserID    int        `json:"user_id" gorm:"column:user_id;not null"`
+	TokenHash string     `json:"-" gorm:"column:token_hash;not null;unique"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	UsedAt    *time.Time `json:"used_at" gorm:"column:used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at;not null"`
+}
+
+func (PasswordResetToken) TableName() string {
+	return "PasswordResetToken"
+}
+
+// Webhook 事件类型
+const (
+	WebhookEventActivityCreated     = "activity.created"
+	WebhookEventActivityCancelled   = "activity.cancelled"
+	WebhookEventApplicationApproved = "application.approved"
+	// WebhookEventAll 订阅所有事件
+	WebhookEventAll = "*"
+)
+
+// Webhook 投递状态
+const (
+	WebhookDeliveryPending   = "pending"   // 待投递（含等待重试）
+	WebhookDeliverySucceeded = "succeeded" // 对方返回2xx
+	WebhookDeliveryFailed    = "failed"    // 超过最大尝试次数
+)
+
+// WebhookEvents 订阅的事件列表，以JSON数组保存
+type WebhookEvents []string
+
+func (e WebhookEvents) Value() (driver.Value, error) {
+	data, err := json.Marshal([]string(e))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (e *WebhookEvents) Scan(value interface{}) error {
+	return scanJSON(value, e)
+}
+
+// Matches 判断是否订阅了该事件
+func (e WebhookEvents) Matches(event string) bool {
+	for _, subscribed := range e {
+		if subscribed == event || subscribed == WebhookEventAll {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSubscription Webhook订阅：事件发生时向URL推送签名的JSON
+type WebhookSubscription struct {
+	SubscriptionID int           `json:"subscription_id" gorm:"column:subscription_id;primaryKey;autoIncrement"`
+	URL            string        `json:"url" gorm:"column:url;not null"`
+	Events         WebhookEvents `json:"events" gorm:"column:events;type:text;not null"`
+	Secret         string        `json:"-" gorm:"column:secret;not null"`
+	Active         bool          `json:"active" gorm:"column:active;not null"`
+	CreatedBy      int           `json:"created_by" gorm:"column:created_by;not null"`
+	CreatedAt      time.Time     `json:"created_at" gorm:"column:created_at;not null"`
+}
+
+func (WebhookSubscription) TableName() string {
+	return "WebhookSubscription"
+}
+
+// WebhookDelivery 一个事件对一个订阅的投递，失败后按指数退避重试
+type WebhookDelivery struct {
+	DeliveryID     int64      `json:"delivery_id" gorm:"column:delivery_id;primaryKey;autoIncrement"`
+	SubscriptionID int        `json:"subscription_id" gorm:"column:subscription_id;not null"`
+	EventID        string     `json:"event_id" gorm:"column:event_id;not null"`
+	Event          string     `json:"event" gorm:"column:event;not null"`
+	Payload        string     `json:"payload" gorm:"column:payload;type:text;not null"`
+	Status         string     `json:"status" gorm:"column:status;not null"`
+	Attempts       int        `json:"attempts" gorm:"column:attempts;not null"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"column:next_attempt_at;not null"`
+	LastStatusCode int        `json:"last_status_code" gorm:"column:last_status_code"`
+	LastError      string     `json:"last_error" gorm:"column:last_error"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"column:created_at;not null"`
+	DeliveredAt    *time.Time `json:"delivered_at" gorm:"column:delivered_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "WebhookDelivery"
+}
+
+// WebhookAttempt 每次投递尝试的结果
+type WebhookAttempt struct {
+	AttemptID   int64     `json:"attempt_id" gorm:"column:attempt_id;primaryKey;autoIncrement"`
+	DeliveryID  int64     `json:"delivery_id" gorm:"column:delivery_id;not null"`
+	AttemptedAt time.Time `json:"attempted_at" gorm:"column:attempted_at;not null"`
+	StatusCode  int       `json:"status_code" gorm:"column:status_code"`
+	Error       string    `json:"error" gorm:"column:error"`
+	DurationMs  int64     `json:"duration_ms" gorm:"column:duration_ms;not null"`
+}
+
+func (WebhookAttempt) TableName() string {
+	return "WebhookAttempt"
+}
+
+type CreateWebhookRequest struct {
+	HandlerID int      `json:"handler_id" binding:"required"`
+	URL       string   `json:"url" binding:"required"`
+	Events    []string `json:"events" binding:"required"`
+	// Secret 签名密钥，为空则自动生成
+	Secret string `json:"secret"`
+}
+
+// WebhookSubscriptionInfo 订阅详情，只在创建时返回密钥
+type WebhookSubscriptionInfo struct {
+	WebhookSubscription
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookDeliveryInfo 投递记录及其每次尝试
+type WebhookDeliveryInfo struct {
+	WebhookDelivery
+	AttemptLog []WebhookAttempt `json:"attempt_log" gorm:"-"`
+}
+
+type ReplayWebhookRequest struct {
+	HandlerID int `json:"handler_id" binding:"required"`
+}
+
+// RealtimeEvent 待各实例分发的实时事件，业务事务提交后写入
+type RealtimeEvent struct {
+	EventID   int64     `json:"event_id" gorm:"column:event_id;primaryKey;autoIncrement"`
+	EventType string    `json:"event_type" gorm:"column:event_type;not null"`
+	Payload   string    `json:"payload" gorm:"column:payload;type:text;not null"`
+	UserID    *int      `json:"user_id" gorm:"column:user_id"`
+	Admins    bool      `json:"admins" gorm:"column:admins;not null"`
+	Broadcast bool      `json:"broadcast" gorm:"column:broadcast;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;not null"`
+}
+
+func (RealtimeEvent) TableName() string {
+	return "RealtimeEvent"
 }
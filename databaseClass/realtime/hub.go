@@ -0,0 +1,132 @@
+package realtime
+
+import (
+	"log"
+	"sync"
+)
+
+// 推送的事件类型
+const (
+	EventActivitySlots       = "activity.slots"
+	EventApplicationStatus   = "application.status"
+	EventApplicationCreated  = "application.created"
+	EventNotificationCreated = "notification.created"
+)
+
+// subscriberBuffer 每个连接缓冲的事件数，客户端消费过慢时丢弃新事件
+const subscriberBuffer = 32
+
+// Event 推送给客户端的事件。
+// UserID非0时推送给该用户；Admins为true时推送给所有管理员；Broadcast为true时推送给所有连接。
+type Event struct {
+	ID   int64       `json:"id"`
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+
+	UserID    int  `json:"-"`
+	Admins    bool `json:"-"`
+	Broadcast bool `json:"-"`
+}
+
+// Subscriber 一个客户端连接的订阅
+type Subscriber struct {
+	userID int
+	admin  bool
+	events chan Event
+}
+
+// Events 返回订阅的事件通道，Hub关闭或取消订阅后通道被关闭
+func (s *Subscriber) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscriber) wants(ev Event) bool {
+	return ev.Broadcast || (ev.UserID != 0 && ev.UserID == s.userID) || (ev.Admins && s.admin)
+}
+
+// Hub 进程内的事件分发中心，只能推送给连接到本实例的客户端；
+// 多实例部署时由业务层把事件写入共享存储，各实例读取后再调用 Publish
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[*Subscriber]struct{}
+	nextID int64
+	closed bool
+}
+
+// NewHub 创建事件分发中心
+func NewHub() *Hub {
+	return &Hub{subs: make(map[*Subscriber]struct{})}
+}
+
+// Subscribe 订阅用户可见的事件，admin为true时同时接收管理员事件
+func (h *Hub) Subscribe(userID int, admin bool) *Subscriber {
+	s := &Subscriber{userID: userID, admin: admin, events: make(chan Event, subscriberBuffer)}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(s.events)
+		return s
+	}
+	h.subs[s] = struct{}{}
+	return s
+}
+
+// Unsubscribe 取消订阅并关闭事件通道，可重复调用
+func (h *Hub) Unsubscribe(s *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[s]; ok {
+		delete(h.subs, s)
+		close(s.events)
+	}
+}
+
+// Publish 分发事件，不阻塞：连接缓冲已满时丢弃该连接的这条事件
+func (h *Hub) Publish(ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.nextID++
+	ev.ID = h.nextID
+	for s := range h.subs {
+		if !s.wants(ev) {
+			continue
+		}
+		select {
+		case s.events <- ev:
+		default:
+			log.Printf("实时事件缓冲已满，丢弃事件 %s (用户ID:%d)", ev.Type, s.userID)
+		}
+	}
+}
+
+// Close 关闭所有订阅，之后的订阅立即结束、发布被忽略
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for s := range h.subs {
+		delete(h.subs, s)
+		close(s.events)
+	}
+}
+
+// Default 默认的事件分发中心
+var Default = NewHub()
+
+// Subscribe 在默认分发中心订阅
+func Subscribe(userID int, admin bool) *Subscriber { return Default.Subscribe(userID, admin) }
+
+// Unsubscribe 在默认分发中心取消订阅
+func Unsubscribe(s *Subscriber) { Default.Unsubscribe(s) }
+
+// Publish 向默认分发中心发布事件
+func Publish(ev Event) { Default.Publish(ev) }
+
+// Shutdown 关闭默认分发中心，服务关闭时结束所有推送连接
+func Shutdown() { Default.Close() }
@@ -42,6 +42,23 @@ func SetupRoutes(r *gin.Engine) {
 	// User routes
 	r.POST("/register", handler.Register)
 	r.POST("/login", handler.Login)
+	r.POST("/password-reset/request", handler.RequestPasswordReset)
+	r.POST("/password-reset/confirm", handler.ResetPassword)
+	r.PUT("/users/:userId/dept", handler.UpdateUserDept)
+	r.PUT("/users/:userId/contact", handler.UpdateUserContact)
+	r.GET("/users/:userId/trainings", handler.ListUserTrainings)
+	r.POST("/users/:userId/trainings", handler.AddUserTraining)
+	r.GET("/users/:userId/hours", handler.GetUserServiceHours)
+	r.POST("/users/:userId/hours/adjustments", handler.AdjustServiceHours)
+	r.GET("/users/:userId/transcript", handler.GetUserTranscript)
+	r.GET("/users/:userId/penalties", handler.ListUserPenalties)
+	r.GET("/users/:userId/teams", handler.ListUserTeams)
+	r.GET("/users/:userId/volunteer-ratings", handler.GetUserVolunteerRatings)
+	r.GET("/users/:userId/notifications", handler.ListNotifications)
+	r.GET("/users/:userId/notifications/unread-count", handler.GetUnreadNotificationCount)
+	r.POST("/users/:userId/notifications/read-all", handler.MarkAllNotificationsRead)
+	r.POST("/users/:userId/notifications/:notificationId/read", handler.MarkNotificationRead)
+	r.GET("/users/:userId/events", handler.StreamUserEvents)
 
 	// Activity routes
 	activityGroup := r.Group("/activities")
@@ -52,23 +69,77 @@ func SetupRoutes(r *gin.Engine) {
 		activityGroup.GET("/popular", handler.GetPopularActivities)
 		activityGroup.GET("/available", handler.GetAvailableActivities)
 		activityGroup.GET("/:id", handler.GetActivityDetail)
+		activityGroup.GET("/:id/eligibility", handler.GetActivityEligibility)
 		activityGroup.POST("", handler.CreateActivity)
 		activityGroup.PUT("/:id", handler.UpdateActivity)
 		activityGroup.DELETE("/:id", handler.DeleteActivity)
 		activityGroup.POST("/:id/apply", handler.ApplyActivity)
+		activityGroup.POST("/:id/team-apply", handler.ApplyAsTeam)
 		activityGroup.GET("/:id/applications", handler.ListActivityApplications)
+		activityGroup.POST("/:id/checkin-codes", handler.GenerateCheckInCode)
+		activityGroup.POST("/:id/checkin", handler.CheckIn)
+		activityGroup.POST("/:id/checkout", handler.CheckOut)
+		activityGroup.GET("/:id/attendance", handler.ListActivityAttendance)
+		activityGroup.POST("/:id/lottery/draw", handler.RunLotteryDraw)
+		activityGroup.GET("/:id/lottery", handler.GetLotteryDraw)
+		activityGroup.GET("/:id/feedback", handler.ListActivityFeedback)
+		activityGroup.POST("/:id/feedback", handler.SubmitActivityFeedback)
 	}
 
 	// Application routes
 	r.GET("/users/:userId/applications", handler.ListUserApplications)
+	r.POST("/applications/bulk-status", handler.BulkReviewApplications)
 	r.POST("/applications/:applicationId/status", handler.UpdateApplicationStatus)
+	r.GET("/applications/:applicationId/timeline", handler.GetApplicationTimeline)
+	r.POST("/applications/:applicationId/attendance", handler.MarkAttendance)
+	r.GET("/applications/:applicationId/certificate", handler.GetActivityCertificate)
+	r.POST("/applications/:applicationId/volunteer-rating", handler.RateVolunteer)
+	r.GET("/certificates/verify/:code", handler.VerifyCertificate)
 	r.DELETE("/applications/:applicationId", handler.CancelApplication)
+	r.POST("/applications/:applicationId/withdraw", handler.CancelApplication)
+
+	// Team routes
+	r.POST("/teams", handler.CreateTeam)
+	r.GET("/teams/:teamId", handler.GetTeam)
+	r.POST("/teams/:teamId/members", handler.AddTeamMember)
+	r.DELETE("/teams/:teamId/members/:userId", handler.RemoveTeamMember)
+	r.GET("/team-applications/:teamApplicationId", handler.GetTeamApplication)
+	r.POST("/team-applications/:teamApplicationId/status", handler.ReviewTeamApplication)
+	r.POST("/team-applications/:teamApplicationId/cancel", handler.CancelTeamApplication)
+
+	// Penalty routes
+	r.GET("/penalties", handler.ListPenalties)
+	r.POST("/penalties/:penaltyId/lift", handler.LiftPenalty)
+
+	// Admin job routes
+	jobGroup := r.Group("/admin/jobs")
+	{
+		jobGroup.GET("", handler.ListJobs)
+		jobGroup.GET("/:name/runs", handler.ListJobRuns)
+		jobGroup.POST("/:name/run", handler.TriggerJob)
+		jobGroup.POST("/:name/pause", handler.PauseJob)
+		jobGroup.POST("/:name/resume", handler.ResumeJob)
+	}
+
+	// Admin webhook routes
+	webhookGroup := r.Group("/admin/webhooks")
+	{
+		webhookGroup.GET("", handler.ListWebhooks)
+		webhookGroup.POST("", handler.CreateWebhook)
+		webhookGroup.DELETE("/:webhookId", handler.DeactivateWebhook)
+		webhookGroup.GET("/:webhookId/deliveries", handler.ListWebhookDeliveries)
+		webhookGroup.POST("/:webhookId/replay-failed", handler.ReplayFailedWebhookDeliveries)
+	}
+	r.POST("/admin/webhook-deliveries/:deliveryId/replay", handler.ReplayWebhookDelivery)
 
 	// Statistics routes
 	r.GET("/statistics", handler.GetStatistics)
 	r.GET("/statistics/departments", handler.GetDeptStatistics)
 	r.GET("/statistics/categories", handler.GetCategoryStatistics)
 	r.GET("/statistics/users", handler.GetUserActivityStatistics)
+	r.GET("/statistics/hours/users", handler.GetUserHoursStatistics)
+	r.GET("/statistics/hours/departments", handler.GetDeptHoursStatistics)
+	r.GET("/statistics/hours/categories", handler.GetCategoryHoursStatistics)
 	r.GET("/statistics/activities/popularity", handler.GetActivityPopularity)
 	r.GET("/statistics/admins", handler.GetAdminCreationStatistics)
 	r.GET("/statistics/omnipotent-volunteers", handler.GetOmnipotentVolunteers)
@@ -0,0 +1,162 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+)
+
+// Leader 判断本实例当前是否负责执行定时任务
+type Leader interface {
+	IsLeader() bool
+}
+
+// Locker 任务执行锁，保证同一任务同一时刻只在一个实例上执行
+type Locker interface {
+	TryLock(ctx context.Context, name string) (unlock func(), ok bool, err error)
+}
+
+// tryGetLock 在指定连接上尝试获取MySQL命名锁（不等待）
+func tryGetLock(ctx context.Context, conn *sql.Conn, name string) (bool, error) {
+	var got sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", name).Scan(&got); err != nil {
+		return false, err
+	}
+	return got.Valid && got.Int64 == 1, nil
+}
+
+func releaseLock(conn *sql.Conn, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", name); err != nil {
+		log.Printf("[scheduler] 释放锁 %s 失败: %v", name, err)
+	}
+}
+
+// MySQLLocker 基于 GET_LOCK 的任务执行锁：执行期间在一条专用连接上持有锁，
+// 实例崩溃时连接断开，锁由MySQL自动释放
+type MySQLLocker struct {
+	db     *sql.DB
+	prefix string
+}
+
+func NewMySQLLocker(db *sql.DB, prefix string) *MySQLLocker {
+	return &MySQLLocker{db: db, prefix: prefix}
+}
+
+func (l *MySQLLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	lockName := l.prefix + name
+	ok, err := tryGetLock(ctx, conn, lockName)
+	if err != nil || !ok {
+		conn.Close()
+		return nil, false, err
+	}
+
+	unlock := func() {
+		releaseLock(conn, lockName)
+		conn.Close()
+	}
+	return unlock, true, nil
+}
+
+// LeaderElector 多实例部署时通过 GET_LOCK 选出一个领导者实例执行定时任务。
+// 领导者在一条专用连接上一直持有锁，并定期确认锁仍属于自己；
+// 领导者退出或与数据库断开时锁自动释放，其他实例在下一次尝试时接任。
+type LeaderElector struct {
+	db            *sql.DB
+	lockName      string
+	retryInterval time.Duration
+
+	// conn 只在 Run 所在的协程中读写；mu 只保护 leader，
+	// 数据库往返期间不持有 mu，避免阻塞调度器的 IsLeader 查询
+	conn   *sql.Conn
+	mu     sync.Mutex
+	leader bool
+}
+
+func NewLeaderElector(db *sql.DB, lockName string, retryInterval time.Duration) *LeaderElector {
+	return &LeaderElector{db: db, lockName: lockName, retryInterval: retryInterval}
+}
+
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+func (e *LeaderElector) setLeader(leader bool) {
+	e.mu.Lock()
+	e.leader = leader
+	e.mu.Unlock()
+}
+
+// Run 定期竞选或确认领导权，ctx取消后主动释放锁
+func (e *LeaderElector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.resign()
+			return
+		case <-ticker.C:
+			e.campaign(ctx)
+		}
+	}
+}
+
+// campaign 非领导者尝试获取锁；领导者确认锁仍由自己的连接持有
+func (e *LeaderElector) campaign(ctx context.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, e.retryInterval)
+	defer cancel()
+
+	if e.conn != nil {
+		var holds sql.NullBool
+		err := e.conn.QueryRowContext(checkCtx, "SELECT IS_USED_LOCK(?) = CONNECTION_ID()", e.lockName).Scan(&holds)
+		if err == nil && holds.Valid && holds.Bool {
+			return
+		}
+		// 先撤销领导者身份，再关闭连接
+		e.setLeader(false)
+		log.Printf("[scheduler] 失去任务领导权: %v", err)
+		e.conn.Close()
+		e.conn = nil
+		return
+	}
+
+	conn, err := e.db.Conn(checkCtx)
+	if err != nil {
+		log.Printf("[scheduler] 竞选任务领导权失败: %v", err)
+		return
+	}
+	ok, err := tryGetLock(checkCtx, conn, e.lockName)
+	if err != nil || !ok {
+		if err != nil {
+			log.Printf("[scheduler] 竞选任务领导权失败: %v", err)
+		}
+		conn.Close()
+		return
+	}
+	e.conn = conn
+	e.setLeader(true)
+	log.Printf("[scheduler] 本实例成为任务领导者")
+}
+
+func (e *LeaderElector) resign() {
+	if e.conn == nil {
+		return
+	}
+	e.setLeader(false)
+	releaseLock(e.conn, e.lockName)
+	e.conn.Close()
+	e.conn = nil
+	log.Printf("[scheduler] 已释放任务领导权")
+}
@@ -0,0 +1,238 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// JobFunc 任务函数，返回本次影响的行数
+type JobFunc func(ctx context.Context) (int64, error)
+
+// Job 按固定间隔执行的命名任务，Timeout大于0时限制单次执行时间
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Timeout  time.Duration
+	Run      JobFunc
+}
+
+// Scheduler 定时任务调度器：每个任务一个goroutine，ctx取消后停止调度并等待正在执行的任务结束。
+// 设置了领导者选举时只有领导者实例执行任务；设置了任务锁时同一任务不会在多个实例上同时执行；
+// 设置了Store时记录每次运行，并跳过已暂停的任务。
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    []*Job
+	started bool
+	wg      sync.WaitGroup
+
+	elector *LeaderElector
+	locker  Locker
+	store   Store
+}
+
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// UseLeaderElection 多实例部署时只由领导者执行任务，须在Start之前调用
+func (s *Scheduler) UseLeaderElection(elector *LeaderElector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.elector = elector
+}
+
+// UseLocker 执行任务前先获取任务锁，须在Start之前调用
+func (s *Scheduler) UseLocker(locker Locker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locker = locker
+}
+
+// UseStore 记录任务运行并读取暂停状态，须在Start之前调用
+func (s *Scheduler) UseStore(store Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = store
+}
+
+// Register 注册任务，须在Start之前调用；timeout为0表示不限制执行时间
+func (s *Scheduler) Register(name string, interval, timeout time.Duration, run JobFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return errors.New("调度器已启动，不能再注册任务")
+	}
+	if name == "" || run == nil {
+		return errors.New("任务名称和任务函数不能为空")
+	}
+	if interval <= 0 {
+		return errors.New("任务执行间隔必须大于0")
+	}
+	if s.find(name) != nil {
+		return errors.New("任务已注册：" + name)
+	}
+
+	s.jobs = append(s.jobs, &Job{Name: name, Interval: interval, Timeout: timeout, Run: run})
+	return nil
+}
+
+func (s *Scheduler) find(name string) *Job {
+	for _, job := range s.jobs {
+		if job.Name == name {
+			return job
+		}
+	}
+	return nil
+}
+
+// Jobs 返回已注册的任务（按注册顺序）
+func (s *Scheduler) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, *job)
+	}
+	return jobs
+}
+
+// Start 启动所有任务：启动时先执行一次，之后按间隔执行
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	if s.elector != nil {
+		// 先竞选一次，领导者实例启动后即可执行任务
+		s.elector.campaign(ctx)
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.elector.Run(ctx)
+		}()
+	}
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Wait 等待所有任务goroutine退出
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.execute(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("[scheduler] 任务 %s 已停止", job.Name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunNow 立即在本实例执行一次任务，不受暂停状态和领导者身份限制，但仍需获取任务锁。
+// 返回本次运行记录ID（未设置Store时为0）；任务本身的错误记录在运行记录中
+func (s *Scheduler) RunNow(ctx context.Context, name string, triggeredBy *int) (int64, error) {
+	s.mu.Lock()
+	job := s.find(name)
+	s.mu.Unlock()
+	if job == nil {
+		return 0, ErrJobNotFound
+	}
+	return s.run(ctx, job, TriggerManual, triggeredBy)
+}
+
+// execute 按调度执行一次任务，非领导者或任务已暂停时跳过
+func (s *Scheduler) execute(ctx context.Context, job *Job) {
+	if ctx.Err() != nil {
+		return
+	}
+	if s.elector != nil && !s.elector.IsLeader() {
+		return
+	}
+	if s.store != nil {
+		paused, err := s.store.IsPaused(ctx, job.Name)
+		if err != nil {
+			log.Printf("[scheduler] 查询任务 %s 的暂停状态失败: %v", job.Name, err)
+			return
+		}
+		if paused {
+			return
+		}
+	}
+
+	if _, err := s.run(ctx, job, TriggerSchedule, nil); err != nil {
+		if errors.Is(err, ErrJobLocked) {
+			log.Printf("[scheduler] 任务 %s 正在其他实例执行，本次跳过", job.Name)
+			return
+		}
+		log.Printf("[scheduler] 任务 %s 未能执行: %v", job.Name, err)
+	}
+}
+
+// run 获取任务锁后执行任务，记录耗时和影响行数
+func (s *Scheduler) run(ctx context.Context, job *Job, trigger string, triggeredBy *int) (int64, error) {
+	if s.locker != nil {
+		unlock, ok, err := s.locker.TryLock(ctx, job.Name)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return 0, ErrJobLocked
+		}
+		defer unlock()
+	}
+
+	var runID int64
+	if s.store != nil {
+		id, err := s.store.StartRun(ctx, job.Name, trigger, triggeredBy)
+		if err != nil {
+			return 0, err
+		}
+		runID = id
+	}
+
+	runCtx := ctx
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	affected, err := job.Run(runCtx)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		log.Printf("[scheduler] 任务 %s 失败，耗时 %s，影响 %d 行: %v", job.Name, elapsed, affected, err)
+	} else {
+		log.Printf("[scheduler] 任务 %s 完成，耗时 %s，影响 %d 行", job.Name, elapsed, affected)
+	}
+
+	if s.store != nil {
+		// 任务因关闭服务被取消时仍要写入结束状态
+		if finishErr := s.store.FinishRun(context.WithoutCancel(ctx), runID, affected, err); finishErr != nil {
+			log.Printf("[scheduler] 记录任务 %s 的运行结果失败: %v", job.Name, finishErr)
+		}
+	}
+	return runID, nil
+}
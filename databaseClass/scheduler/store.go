@@ -0,0 +1,26 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+)
+
+// 任务触发方式
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+var (
+	ErrJobNotFound = errors.New("任务不存在")
+	ErrJobLocked   = errors.New("任务正在执行，请稍后再试")
+)
+
+// Store 持久化任务的运行记录和暂停状态，多个实例共享同一份数据
+type Store interface {
+	IsPaused(ctx context.Context, name string) (bool, error)
+	// StartRun 记录一次运行开始，返回运行记录ID
+	StartRun(ctx context.Context, name, trigger string, triggeredBy *int) (int64, error)
+	// FinishRun 记录运行结束时间、影响行数和错误
+	FinishRun(ctx context.Context, runID int64, affected int64, runErr error) error
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/model"
+	"volunteer-system/utils"
+)
+
+// publishedActivityStatuses 已发布且未过期的活动状态，活动列表只显示这些活动
+var publishedActivityStatuses = []string{model.ActivityStatusActive, model.ActivityStatusRegistrationClosed}
+
+// parsePublishAt 解析定时发布时间，须早于活动开始时间和报名截止时间
+func parsePublishAt(raw string, activityTime time.Time, deadline *time.Time) (*time.Time, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	publishAt, err := utils.ParseActivityTime(raw)
+	if err != nil {
+		return nil, errors.New("发布时间格式不正确")
+	}
+	if !publishAt.Before(activityTime) {
+		return nil, errors.New("发布时间必须早于活动开始时间")
+	}
+	if deadline != nil && !publishAt.Before(*deadline) {
+		return nil, errors.New("发布时间必须早于报名截止时间")
+	}
+	return &publishAt, nil
+}
+
+// publishStatus 根据发布时间确定活动状态：发布时间未到为待发布，否则待发布的活动转为报名中。
+// 已有报名的活动不能推迟发布
+func publishStatus(activity *model.Activity, publishAt *time.Time) (string, error) {
+	current := activity.Status
+	if current == "" {
+		current = model.ActivityStatusActive
+	}
+	if current != model.ActivityStatusScheduled && current != model.ActivityStatusActive {
+		return current, nil
+	}
+
+	if publishAt != nil && publishAt.After(time.Now()) {
+		if current == model.ActivityStatusActive && activity.ActivityID != 0 {
+			var count int64
+			if err := config.DB.Model(&model.Application{}).
+				Where("activity_id = ?", activity.ActivityID).
+				Count(&count).Error; err != nil {
+				return "", errors.New("查询活动报名失败")
+			}
+			if count > 0 {
+				return "", errors.New("活动已有报名，不能推迟发布")
+			}
+		}
+		return model.ActivityStatusScheduled, nil
+	}
+	return model.ActivityStatusActive, nil
+}
+
+// PublishScheduledActivities 定时任务：发布时间已到的待发布活动开放报名，返回发布的活动数
+func PublishScheduledActivities(ctx context.Context) (int64, error) {
+	result := config.DB.WithContext(ctx).Model(&model.Activity{}).
+		Where("status = ? AND publish_at <= ?", model.ActivityStatusScheduled, time.Now()).
+		Update("status", model.ActivityStatusActive)
+	if result.Error != nil {
+		return 0, errors.New("发布活动失败")
+	}
+	return result.RowsAffected, nil
+}
+
+// activityFullCondition 活动占用名额的报名数已达上限（抽签活动截止前不限人数，不算满员）
+const activityFullCondition = `approval_mode <> 'lottery' AND (
+	SELECT COUNT(*) FROM Application app
+	WHERE app.activity_id = Activity.activity_id AND app.current_status IN ?
+) >= max_people`
+
+// SyncRegistrationStatus 定时任务：报名截止或满员的活动关闭报名；
+// 名额释放或截止时间延后的活动在开始前重新开放报名。返回状态变化的活动数
+func SyncRegistrationStatus(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	closed := config.DB.WithContext(ctx).Model(&model.Activity{}).
+		Where("status = ?", model.ActivityStatusActive).
+		Where("(registration_deadline IS NOT NULL AND registration_deadline <= ?) OR ("+activityFullCondition+")",
+			now, slotHoldingStatuses).
+		Update("status", model.ActivityStatusRegistrationClosed)
+	if closed.Error != nil {
+		return 0, errors.New("关闭报名失败")
+	}
+
+	reopened := config.DB.WithContext(ctx).Model(&model.Activity{}).
+		Where("status = ? AND activity_time > ?", model.ActivityStatusRegistrationClosed, now).
+		Where("registration_deadline IS NULL OR registration_deadline > ?", now).
+		Where("NOT ("+activityFullCondition+")", slotHoldingStatuses).
+		Update("status", model.ActivityStatusActive)
+	if reopened.Error != nil {
+		return closed.RowsAffected, errors.New("重新开放报名失败")
+	}
+	return closed.RowsAffected + reopened.RowsAffected, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"fmt"
 
 	"volunteer-system/config"
 	"volunteer-system/model"
@@ -25,14 +26,17 @@ type ActivityDetail struct {
 	CategoryName string `json:"category_name"`
 	CreatorID    int    `json:"creator_id"`
 	CreatorName  string `json:"creator_name"`
+	// 志愿者评分的平均分和评价人数
+	AvgRating   float64 `json:"avg_rating"`
+	RatingCount int     `json:"rating_count"`
 }
 
 func ListActivities(deptID, categoryID *int) ([]model.Activity, error) {
 	var activities []model.Activity
 	query := config.DB.Model(&model.Activity{})
 
-	// 只返回活跃的活动，不显示已过期或已关闭的活动
-	query = query.Where("status = ?", "active")
+	// 只返回已发布的活动（报名中或报名已关闭），不显示待发布、已过期或已关闭的活动
+	query = query.Where("status IN ?", publishedActivityStatuses)
 
 	if deptID != nil {
 		query = query.Where("dept_id = ?", *deptID)
@@ -54,6 +58,31 @@ func CreateActivity(req *model.CreateActivityRequest) (*model.Activity, error) {
 		return nil, errors.New("活动时间格式不正确")
 	}
 
+	if err := validateEligibilityRules(req.EligibilityRules); err != nil {
+		return nil, err
+	}
+	if err := validateFormSchema(req.FormSchema); err != nil {
+		return nil, err
+	}
+
+	durationMinutes, err := normalizeDuration(req.DurationMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	approvalMode, err := normalizeApprovalMode(req.ApprovalMode, req.AutoApproveRules)
+	if err != nil {
+		return nil, err
+	}
+	deadline, err := parseRegistrationDeadline(req.RegistrationDeadline, activityTime, approvalMode)
+	if err != nil {
+		return nil, err
+	}
+	publishAt, err := parsePublishAt(req.PublishAt, activityTime, deadline)
+	if err != nil {
+		return nil, err
+	}
+
 	activity := model.Activity{
 		DeptID:       req.DeptID,
 		CategoryID:   req.CategoryID,
@@ -63,10 +92,28 @@ func CreateActivity(req *model.CreateActivityRequest) (*model.Activity, error) {
 		ActivityTime: activityTime,
 		Location:     req.Location,
 		MaxPeople:    req.MaxPeople,
+
+		DurationMinutes:  durationMinutes,
+		EligibilityRules: req.EligibilityRules,
+		FormSchema:       req.FormSchema,
+		ApprovalMode:     approvalMode,
+		AutoApproveRules: req.AutoApproveRules,
+
+		RegistrationDeadline: deadline,
+		PublishAt:            publishAt,
+	}
+	if activity.Status, err = publishStatus(&activity, publishAt); err != nil {
+		return nil, err
 	}
 
-	if err := config.DB.Create(&activity).Error; err != nil {
-		return nil, errors.New("创建活动失败")
+	err = transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&activity).Error; err != nil {
+			return errors.New("创建活动失败")
+		}
+		return publishWebhookEvent(tx, model.WebhookEventActivityCreated, activity)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &activity, nil
@@ -78,6 +125,31 @@ func UpdateActivity(activityID int, req *model.UpdateActivityRequest) (*model.Ac
 		return nil, errors.New("活动时间格式不正确")
 	}
 
+	if err := validateEligibilityRules(req.EligibilityRules); err != nil {
+		return nil, err
+	}
+	if err := validateFormSchema(req.FormSchema); err != nil {
+		return nil, err
+	}
+
+	durationMinutes, err := normalizeDuration(req.DurationMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	approvalMode, err := normalizeApprovalMode(req.ApprovalMode, req.AutoApproveRules)
+	if err != nil {
+		return nil, err
+	}
+	deadline, err := parseRegistrationDeadline(req.RegistrationDeadline, activityTime, approvalMode)
+	if err != nil {
+		return nil, err
+	}
+	publishAt, err := parsePublishAt(req.PublishAt, activityTime, deadline)
+	if err != nil {
+		return nil, err
+	}
+
 	var activity model.Activity
 	if err := config.DB.First(&activity, "activity_id = ?", activityID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -86,6 +158,10 @@ func UpdateActivity(activityID int, req *model.UpdateActivityRequest) (*model.Ac
 		return nil, errors.New("查询活动失败")
 	}
 
+	// 标题、时间、地点或时长变更时通知报名者
+	changed := activity.Title != req.Title || !activity.ActivityTime.Equal(activityTime) ||
+		activity.Location != req.Location || activity.DurationMinutes != durationMinutes
+
 	activity.DeptID = req.DeptID
 	activity.CategoryID = req.CategoryID
 	activity.CreatorID = req.CreatorID
@@ -94,36 +170,141 @@ func UpdateActivity(activityID int, req *model.UpdateActivityRequest) (*model.Ac
 	activity.ActivityTime = activityTime
 	activity.Location = req.Location
 	activity.MaxPeople = req.MaxPeople
+	activity.DurationMinutes = durationMinutes
+	activity.EligibilityRules = req.EligibilityRules
+	activity.FormSchema = req.FormSchema
+	activity.ApprovalMode = approvalMode
+	activity.AutoApproveRules = req.AutoApproveRules
+	activity.RegistrationDeadline = deadline
+	if activity.Status, err = publishStatus(&activity, publishAt); err != nil {
+		return nil, err
+	}
+	activity.PublishAt = publishAt
 
-	if err := config.DB.Save(&activity).Error; err != nil {
-		return nil, errors.New("更新活动失败")
+	err = transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&activity).Error; err != nil {
+			return errors.New("更新活动失败")
+		}
+		if !changed {
+			return nil
+		}
+		return notifyApplicants(tx, activity.ActivityID, "活动信息变更",
+			fmt.Sprintf("您报名的活动“%s”信息已更新：时间 %s，地点 %s，时长 %d 分钟",
+				activity.Title, activity.ActivityTime.Format("2006-01-02 15:04"), activity.Location, activity.DurationMinutes), true)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &activity, nil
 }
 
-func DeleteActivity(activityID int) error {
-	// 先删除所有相关的应用状态日志
-	if err := config.DB.Delete(&model.ApplicationStatusLog{}, "application_id IN (SELECT application_id FROM Application WHERE activity_id = ?)", activityID).Error; err != nil {
-		return errors.New("删除状态日志失败")
-	}
+// defaultDurationMinutes 未填写活动时长时的默认值
+const defaultDurationMinutes = 120
 
-	// 再删除所有相关的报名记录
-	if err := config.DB.Delete(&model.Application{}, "activity_id = ?", activityID).Error; err != nil {
-		return errors.New("删除报名记录失败")
+func normalizeDuration(minutes int) (int, error) {
+	if minutes < 0 {
+		return 0, errors.New("活动时长不能为负数")
+	}
+	if minutes == 0 {
+		return defaultDurationMinutes, nil
 	}
+	return minutes, nil
+}
 
-	// 最后删除活动
-	if err := config.DB.Delete(&model.Activity{}, "activity_id = ?", activityID).Error; err != nil {
-		return errors.New("删除活动失败")
+func DeleteActivity(activityID int) error {
+	var activity model.Activity
+	if err := config.DB.First(&activity, "activity_id = ?", activityID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("活动不存在")
+		}
+		return errors.New("查询活动失败")
 	}
-	return nil
+
+	// 取消通知、Webhook和删除在同一事务中，删除失败时不会发出取消消息
+	return transaction(func(tx *gorm.DB) error {
+		// 通知报名仍有效的用户活动已取消，通知不再关联将被删除的活动和报名
+		if err := notifyApplicants(tx, activityID, "活动已取消",
+			fmt.Sprintf("您报名的活动“%s”（%s）已取消", activity.Title, activity.ActivityTime.Format("2006-01-02 15:04")), false); err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Notification{}).
+			Where("activity_id = ? OR application_id IN (SELECT application_id FROM Application WHERE activity_id = ?)", activityID, activityID).
+			Updates(map[string]interface{}{"activity_id": nil, "application_id": nil}).Error; err != nil {
+			return errors.New("解除通知关联失败")
+		}
+		if err := publishWebhookEvent(tx, model.WebhookEventActivityCancelled, activity); err != nil {
+			return err
+		}
+
+		// 服务时长台账保留，只解除与活动和报名的关联
+		if err := tx.Model(&model.ServiceHoursEntry{}).
+			Where("activity_id = ?", activityID).
+			Updates(map[string]interface{}{"activity_id": nil, "application_id": nil}).Error; err != nil {
+			return errors.New("解除服务时长关联失败")
+		}
+
+		// 已签发的证明保留，可继续查验
+		if err := tx.Model(&model.Certificate{}).
+			Where("application_id IN (SELECT application_id FROM Application WHERE activity_id = ?)", activityID).
+			Update("application_id", nil).Error; err != nil {
+			return errors.New("解除证明关联失败")
+		}
+
+		// 删除考勤记录和签到码
+		if err := tx.Delete(&model.Attendance{}, "activity_id = ?", activityID).Error; err != nil {
+			return errors.New("删除考勤记录失败")
+		}
+		if err := tx.Delete(&model.CheckInCode{}, "activity_id = ?", activityID).Error; err != nil {
+			return errors.New("删除签到码失败")
+		}
+
+		// 删除抽签记录
+		if err := tx.Delete(&model.LotteryDrawEntry{}, "draw_id IN (SELECT draw_id FROM LotteryDraw WHERE activity_id = ?)", activityID).Error; err != nil {
+			return errors.New("删除抽签结果失败")
+		}
+		if err := tx.Delete(&model.LotteryDraw{}, "activity_id = ?", activityID).Error; err != nil {
+			return errors.New("删除抽签记录失败")
+		}
+
+		// 删除提醒记录
+		if err := tx.Delete(&model.ReminderLog{}, "activity_id = ?", activityID).Error; err != nil {
+			return errors.New("删除提醒记录失败")
+		}
+
+		// 删除评分和评价
+		if err := tx.Delete(&model.ActivityFeedback{}, "activity_id = ?", activityID).Error; err != nil {
+			return errors.New("删除活动反馈失败")
+		}
+		if err := tx.Delete(&model.VolunteerRating{}, "activity_id = ?", activityID).Error; err != nil {
+			return errors.New("删除志愿者评价失败")
+		}
+
+		// 先删除所有相关的应用状态日志
+		if err := tx.Delete(&model.ApplicationStatusLog{}, "application_id IN (SELECT application_id FROM Application WHERE activity_id = ?)", activityID).Error; err != nil {
+			return errors.New("删除状态日志失败")
+		}
+
+		// 再删除所有相关的报名记录
+		if err := tx.Delete(&model.Application{}, "activity_id = ?", activityID).Error; err != nil {
+			return errors.New("删除报名记录失败")
+		}
+		if err := tx.Delete(&model.TeamApplication{}, "activity_id = ?", activityID).Error; err != nil {
+			return errors.New("删除团队报名失败")
+		}
+
+		// 最后删除活动
+		if err := tx.Delete(&model.Activity{}, "activity_id = ?", activityID).Error; err != nil {
+			return errors.New("删除活动失败")
+		}
+		return nil
+	})
 }
 
 // SearchActivities 搜索活动
 func SearchActivities(keyword string) ([]model.Activity, error) {
 	var activities []model.Activity
-	if err := config.DB.Where("status = ? AND title LIKE ?", "active", "%"+keyword+"%").
+	if err := config.DB.Where("status IN ? AND title LIKE ?", publishedActivityStatuses, "%"+keyword+"%").
 		Order("activity_time desc").
 		Find(&activities).Error; err != nil {
 		return nil, errors.New("搜索活动失败")
@@ -141,7 +322,9 @@ func GetActivityDetail(activityID int) (*ActivityDetail, error) {
 			a.max_people, a.status,
 			a.dept_id, COALESCE(d.dept_name, '') as dept_name,
 			a.category_id, COALESCE(ac.category_name, '') as category_name,
-			a.creator_id, COALESCE(u.username, '') as creator_name
+			a.creator_id, COALESCE(u.username, '') as creator_name,
+			COALESCE((SELECT ROUND(AVG(f.rating), 2) FROM ActivityFeedback f WHERE f.activity_id = a.activity_id), 0) as avg_rating,
+			(SELECT COUNT(*) FROM ActivityFeedback f WHERE f.activity_id = a.activity_id) as rating_count
 		FROM Activity a
 		LEFT JOIN Dept d ON a.dept_id = d.dept_id
 		LEFT JOIN ActivityCategory ac ON a.category_id = ac.category_id
@@ -190,31 +373,76 @@ func GetAvailableActivities(userID int) ([]AvailableActivity, error) {
 			COALESCE(d.dept_name, '未分配') as dept_name,
 			COALESCE(ac.category_name, '未分类') as category_name
 		FROM Activity a
-		LEFT JOIN Application app ON a.activity_id = app.activity_id
+		LEFT JOIN Application app ON a.activity_id = app.activity_id AND app.current_status NOT IN ?
 		LEFT JOIN Dept d ON a.dept_id = d.dept_id
 		LEFT JOIN ActivityCategory ac ON a.category_id = ac.category_id
 		WHERE a.status = 'active'
+		AND (a.registration_deadline IS NULL OR a.registration_deadline > NOW())
 		AND a.activity_id NOT IN (
-			SELECT DISTINCT activity_id FROM Application WHERE user_id = ?
+			-- 撤回后允许重新报名时，已撤回的活动仍可申请
+			SELECT DISTINCT activity_id FROM Application
+			WHERE user_id = ? AND NOT (current_status = 'withdrawn' AND ?)
 		)
 		AND a.activity_id NOT IN (
 			-- 自连接：排除与用户已申请活动时间冲突的
 			SELECT DISTINCT a2.activity_id
 			FROM Activity a2
-			JOIN Activity a1 ON ABS(HOUR(TIMEDIFF(a1.activity_time, a2.activity_time))) < 2
+			JOIN Activity a1 ON `+activityConflictCondition+`
 			WHERE a1.activity_id IN (
-				SELECT DISTINCT activity_id FROM Application WHERE user_id = ?
-			) AND a1.status = 'active' AND a2.status = 'active'
+				SELECT DISTINCT activity_id FROM Application WHERE user_id = ? AND current_status NOT IN ?
+			) AND a1.status IN ('active', 'registration_closed') AND a2.status = 'active'
 		)
 		GROUP BY a.activity_id, a.title, a.description, a.location, a.activity_time, 
-			a.max_people, d.dept_name, ac.category_name
-		HAVING remaining_slots > 0
+			a.max_people, a.approval_mode, d.dept_name, ac.category_name
+		-- 抽签活动报名截止前不限人数，截止后统一抽签
+		HAVING remaining_slots > 0 OR a.approval_mode = 'lottery'
 		ORDER BY a.activity_time ASC
-	`, userID, userID).Scan(&activities).Error
+	`, releasedStatuses, userID, config.Policy.AllowReapplyAfterWithdrawal, userID, releasedStatuses).Scan(&activities).Error
 
 	if err != nil {
 		return nil, errors.New("获取可申请活动列表失败")
 	}
 
-	return activities, nil
+	return filterEligibleActivities(userID, activities)
+}
+
+// filterEligibleActivities 排除用户不满足资格规则的活动
+func filterEligibleActivities(userID int, activities []AvailableActivity) ([]AvailableActivity, error) {
+	if len(activities) == 0 {
+		return activities, nil
+	}
+
+	ids := make([]int, 0, len(activities))
+	for _, a := range activities {
+		ids = append(ids, a.ActivityID)
+	}
+
+	var ruled []model.Activity
+	if err := config.DB.Select("activity_id, eligibility_rules").
+		Where("activity_id IN ? AND eligibility_rules IS NOT NULL", ids).
+		Find(&ruled).Error; err != nil {
+		return nil, errors.New("查询活动报名条件失败")
+	}
+	if len(ruled) == 0 {
+		return activities, nil
+	}
+
+	rulesByActivity := make(map[int]model.EligibilityRules, len(ruled))
+	for _, a := range ruled {
+		rulesByActivity[a.ActivityID] = a.EligibilityRules
+	}
+
+	profile, err := loadVolunteerProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	eligible := make([]AvailableActivity, 0, len(activities))
+	for _, a := range activities {
+		if _, failed := profile.check(rulesByActivity[a.ActivityID]); failed != nil {
+			continue
+		}
+		eligible = append(eligible, a)
+	}
+	return eligible, nil
 }
@@ -2,28 +2,94 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"volunteer-system/config"
 	"volunteer-system/model"
+	"volunteer-system/realtime"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func ApplyActivity(userID, activityID int) (*model.Application, error) {
-	var user model.User
-	if err := config.DB.First(&user, "user_id = ?", userID).Error; err != nil {
-		return nil, errors.New("用户不存在")
+func ApplyActivity(activityID int, req *model.ApplyActivityRequest) (*model.Application, error) {
+	userID := req.UserID
+
+	activity, err := loadOpenActivity(activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 只有管理员代为报名时才能忽略时间冲突
+	if err := requireConflictOverride(req.OverrideConflict, req.HandlerID); err != nil {
+		return nil, err
+	}
+
+	applicant, err := prepareApplicant(userID, activity, req.Answers)
+	if err != nil {
+		return nil, err
+	}
+
+	autoApprove, autoComment, err := autoApproval(userID, activity)
+	if err != nil {
+		return nil, err
+	}
+
+	var application *model.Application
+	err = transaction(func(tx *gorm.DB) error {
+		// 锁定活动行，名额检查和自动批准与人工批准串行执行，避免超员
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(activity, "activity_id = ?", activityID).Error; err != nil {
+			return errors.New("查询活动信息失败")
+		}
+
+		// 抽签活动截止前收集所有报名，不检查名额
+		if activity.ApprovalMode != model.ApprovalModeLottery {
+			approvedCount, err := countSlotHolders(tx, activityID)
+			if err != nil {
+				return err
+			}
+			if approvedCount >= int64(activity.MaxPeople) {
+				return errors.New("活动人数已满")
+			}
+		}
+
+		var err error
+		application, err = submitApplication(tx, activity, applicant, req.OverrideConflict, nil)
+		if err != nil {
+			return err
+		}
+
+		if autoApprove {
+			return changeApplicationStatus(tx, application, model.AppStatusApproved, nil, autoComment)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	return application, nil
+}
+
+// loadOpenActivity 查询活动并检查是否仍在报名中
+func loadOpenActivity(activityID int) (*model.Activity, error) {
 	var activity model.Activity
 	if err := config.DB.First(&activity, "activity_id = ?", activityID).Error; err != nil {
 		return nil, errors.New("活动不存在")
 	}
 
-	// 检查活动是否已过期
-	if activity.Status != "active" {
+	// 检查活动是否已发布、是否仍在报名中
+	switch activity.Status {
+	case model.ActivityStatusActive:
+	case model.ActivityStatusScheduled:
+		return nil, errors.New("活动尚未发布，不能申请")
+	case model.ActivityStatusRegistrationClosed:
+		return nil, errors.New("活动报名已关闭")
+	default:
 		return nil, errors.New("活动已关闭，不能申请")
 	}
 
@@ -32,57 +98,139 @@ func ApplyActivity(userID, activityID int) (*model.Application, error) {
 		return nil, errors.New("活动已过期，不能申请")
 	}
 
-	var existingCount int64
-	if err := config.DB.Model(&model.Application{}).
-		Where("user_id = ? AND activity_id = ?", userID, activityID).
-		Count(&existingCount).Error; err != nil {
+	if activity.RegistrationDeadline != nil && activity.RegistrationDeadline.Before(time.Now()) {
+		return nil, errors.New("报名已截止")
+	}
+	return &activity, nil
+}
+
+// applicant 通过报名前检查的报名人
+type applicant struct {
+	UserID   int
+	Username string
+	Answers  model.FormAnswers
+	// Existing 已撤回、可重新报名的原报名记录
+	Existing *model.Application
+}
+
+// prepareApplicant 报名前检查：用户存在、未被限制报名、满足资格规则、表单填写正确、没有重复报名
+func prepareApplicant(userID int, activity *model.Activity, answers model.FormAnswers) (*applicant, error) {
+	var user model.User
+	if err := config.DB.First(&user, "user_id = ?", userID).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	if err := ensureNotPenalized(userID); err != nil {
+		return nil, err
+	}
+
+	if err := ensureEligible(userID, activity); err != nil {
+		return nil, err
+	}
+
+	answers, err := validateFormAnswers(activity.FormSchema, answers)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &applicant{UserID: userID, Username: user.Username, Answers: answers}
+
+	var existing model.Application
+	err = config.DB.Where("user_id = ? AND activity_id = ?", userID, activity.ActivityID).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, errors.New("查询报名记录失败")
 	}
-	if existingCount > 0 {
-		return nil, errors.New("您已申请参加该活动")
+	if err == nil {
+		if existing.CurrentStatus != model.AppStatusWithdrawn {
+			return nil, errors.New("您已申请参加该活动")
+		}
+		if !config.Policy.AllowReapplyAfterWithdrawal {
+			return nil, errors.New("撤回报名后不能再次申请该活动")
+		}
+		result.Existing = &existing
 	}
+	return result, nil
+}
 
-	var approvedCount int64
-	if err := config.DB.Model(&model.Application{}).
-		Where("activity_id = ? AND current_status = ?", activityID, "approved").
-		Count(&approvedCount).Error; err != nil {
-		return nil, errors.New("查询活动报名人数失败")
+// submitApplication 在事务中检查时间冲突并写入待审核报名（新建，或撤回后重新报名）
+func submitApplication(tx *gorm.DB, activity *model.Activity, a *applicant, overrideConflict bool, teamApplicationID *int) (*model.Application, error) {
+	// 与用户其他未释放的报名时间冲突时不能报名
+	conflictNote, err := checkTimeConflict(tx, a.UserID, activity, activeApplicationStatuses, overrideConflict)
+	if err != nil {
+		return nil, err
 	}
-	if approvedCount >= int64(activity.MaxPeople) {
-		return nil, errors.New("活动人数已满")
+
+	var application model.Application
+	if a.Existing != nil {
+		application = *a.Existing
+		if err := reapplyActivity(tx, &application, a.Answers, teamApplicationID, conflictNote); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := createApplication(tx, &application, a.UserID, activity.ActivityID, a.Answers, teamApplicationID, conflictNote); err != nil {
+			return nil, err
+		}
 	}
+	return &application, nil
+}
 
+// createApplication 新建待审核的报名记录及首条状态日志
+func createApplication(tx *gorm.DB, app *model.Application, userID, activityID int, answers model.FormAnswers, teamApplicationID *int, note string) error {
 	now := time.Now()
 
-	application := model.Application{
+	*app = model.Application{
 		UserID:        userID,
 		ActivityID:    activityID,
 		ApplyTime:     now,
-		CurrentStatus: "pending",
+		CurrentStatus: model.AppStatusPending,
+		Answers:       answers,
+
+		TeamApplicationID: teamApplicationID,
 	}
 
-	if err := config.DB.Create(&application).Error; err != nil {
-		return nil, errors.New("报名失败")
+	if err := tx.Create(app).Error; err != nil {
+		return errors.New("报名失败")
 	}
 
 	log := model.ApplicationStatusLog{
-		ApplicationID: application.ApplicationID,
+		ApplicationID: app.ApplicationID,
 		HandlerID:     &userID,
-		LogStatus:     "pending",
+		LogStatus:     model.AppStatusPending,
 		HandleTime:    now,
+		InternalNote:  note,
 	}
 
-	if err := config.DB.Create(&log).Error; err != nil {
-		return nil, errors.New("保存报名日志失败")
+	if err := tx.Create(&log).Error; err != nil {
+		return errors.New("保存报名日志失败")
 	}
 
-	return &application, nil
+	publishEvent(tx, realtime.Event{
+		Type:   realtime.EventApplicationCreated,
+		Admins: true,
+		Data:   applicationEventData(app),
+	})
+	return publishActivitySlots(tx, activityID)
+}
+
+// reapplyActivity 撤回后重新报名：复用原报名记录，状态回到待审核
+func reapplyActivity(tx *gorm.DB, app *model.Application, answers model.FormAnswers, teamApplicationID *int, note string) error {
+	now := time.Now()
+	if err := tx.Model(&model.Application{}).
+		Where("application_id = ?", app.ApplicationID).
+		Updates(map[string]interface{}{"apply_time": now, "answers": answers, "team_application_id": teamApplicationID}).Error; err != nil {
+		return errors.New("报名失败")
+	}
+	app.ApplyTime = now
+	app.Answers = answers
+	app.TeamApplicationID = teamApplicationID
+
+	return changeApplicationStatusWithNote(tx, app, model.AppStatusPending, &app.UserID, "重新报名", note)
 }
 
 func ListActivityApplications(activityID int) ([]model.ActivityApplicationWithUser, error) {
 	var apps []model.ActivityApplicationWithUser
 	if err := config.DB.Table("Application").
-		Select("Application.application_id, Application.user_id, User.username, Application.apply_time, Application.current_status").
+		Select("Application.application_id, Application.user_id, User.username, Application.apply_time, Application.current_status, Application.answers, Application.attendance_status, Application.team_application_id").
 		Joins("JOIN User ON Application.user_id = User.user_id").
 		Where("Application.activity_id = ?", activityID).
 		Order("Application.apply_time DESC").
@@ -96,7 +244,8 @@ func ListActivityApplications(activityID int) ([]model.ActivityApplicationWithUs
 func ListUserApplications(userID int) ([]model.UserApplicationInfo, error) {
 	var apps []model.UserApplicationInfo
 	if err := config.DB.Table("Application").
-		Select("Application.application_id, Application.activity_id, Activity.title, Activity.activity_time, Activity.location, Application.current_status, Application.apply_time").
+		Select(`Application.application_id, Application.activity_id, Activity.title, Activity.activity_time, Activity.location, Application.current_status, Application.apply_time, Application.attendance_status,
+			(SELECT l.comment FROM ApplicationStatusLog l WHERE l.application_id = Application.application_id ORDER BY l.log_id DESC LIMIT 1) AS status_comment`).
 		Joins("JOIN Activity ON Application.activity_id = Activity.activity_id").
 		Where("Application.user_id = ?", userID).
 		Order("Application.apply_time DESC").
@@ -107,62 +256,139 @@ func ListUserApplications(userID int) ([]model.UserApplicationInfo, error) {
 	return apps, nil
 }
 
-func UpdateApplicationStatus(appID int, status string, handlerID int) error {
-	status = strings.ToLower(strings.TrimSpace(status))
-	if status != "approved" && status != "rejected" && status != "pending" {
-		return errors.New("状态只能是 approved / rejected / pending")
+// reviewableStatuses 管理员审核时可设置的状态
+var reviewableStatuses = map[string]bool{
+	model.AppStatusApproved:             true,
+	model.AppStatusRejected:             true,
+	model.AppStatusWaitlisted:           true,
+	model.AppStatusCancelledByOrganizer: true,
+}
+
+// normalizeReview 校验审核状态和审核意见，拒绝时必须填写原因
+// maxCommentRunes 审核意见、撤回原因的最大字数；状态日志comment列为VARCHAR(500)，留出系统前缀的余量
+const maxCommentRunes = 400
+
+// normalizeComment 去掉首尾空白并检查长度，what为提示中的名称
+func normalizeComment(comment, what string) (string, error) {
+	comment = strings.TrimSpace(comment)
+	if utf8.RuneCountInString(comment) > maxCommentRunes {
+		return "", fmt.Errorf("%s不能超过%d字", what, maxCommentRunes)
 	}
+	return comment, nil
+}
 
-	var app model.Application
-	if err := config.DB.First(&app, "application_id = ?", appID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("报名记录不存在")
+func normalizeReview(status, comment string) (string, string, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if !reviewableStatuses[status] {
+		return "", "", &CodedError{
+			Code:    ErrCodeInvalidStatus,
+			Message: "状态只能是 approved / rejected / waitlisted / cancelled_by_organizer，考勤请通过签到或考勤登记",
 		}
-		return errors.New("查询报名记录失败")
 	}
 
-	if status == "approved" && app.CurrentStatus != "approved" {
-		var activity model.Activity
-		if err := config.DB.First(&activity, "activity_id = ?", app.ActivityID).Error; err != nil {
-			return errors.New("查询活动信息失败")
-		}
+	comment, err := normalizeComment(comment, "审核意见")
+	if err != nil {
+		return "", "", err
+	}
+	if status == model.AppStatusRejected && comment == "" {
+		return "", "", errors.New("拒绝报名时必须填写拒绝原因")
+	}
+	return status, comment, nil
+}
 
-		var approvedCount int64
-		if err := config.DB.Model(&model.Application{}).
-			Where("activity_id = ? AND current_status = ?", app.ActivityID, "approved").
-			Count(&approvedCount).Error; err != nil {
-			return errors.New("查询活动报名人数失败")
+func UpdateApplicationStatus(appID int, status string, handlerID int, comment string, overrideConflict bool) error {
+	status, comment, err := normalizeReview(status, comment)
+	if err != nil {
+		return err
+	}
+	if err := requireConflictOverride(overrideConflict, &handlerID); err != nil {
+		return err
+	}
+
+	return transaction(func(tx *gorm.DB) error {
+		var app model.Application
+		if err := tx.First(&app, "application_id = ?", appID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("报名记录不存在")
+			}
+			return errors.New("查询报名记录失败")
 		}
 
-		if approvedCount >= int64(activity.MaxPeople) {
-			return errors.New("活动人数已满，无法再通过报名")
+		if status == model.AppStatusApproved {
+			if app.TeamApplicationID != nil {
+				return errTeamApproval
+			}
+
+			// 锁定活动行，串行化同一活动的批准操作，避免超员
+			var activity model.Activity
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				First(&activity, "activity_id = ?", app.ActivityID).Error; err != nil {
+				return errors.New("查询活动信息失败")
+			}
+
+			approvedCount, err := countSlotHolders(tx, app.ActivityID)
+			if err != nil {
+				return err
+			}
+			if approvedCount >= int64(activity.MaxPeople) {
+				return errors.New("活动人数已满，无法再通过报名")
+			}
+
+			// 不能批准与该用户其他已批准活动时间冲突的报名
+			note, err := checkTimeConflict(tx, app.UserID, &activity, slotHoldingStatuses, overrideConflict)
+			if err != nil {
+				return err
+			}
+			return changeApplicationStatusWithNote(tx, &app, status, &handlerID, comment, note)
 		}
-	}
 
-	now := time.Now()
+		return changeApplicationStatus(tx, &app, status, &handlerID, comment)
+	})
+}
 
-	if err := config.DB.Model(&model.Application{}).
-		Where("application_id = ?", appID).
-		Update("current_status", status).Error; err != nil {
-		return errors.New("更新报名状态失败")
+// GetApplicationTimeline 获取报名的完整状态变更历史（含处理人用户名）
+// viewerID为管理员时才返回系统说明（internal_note）
+func GetApplicationTimeline(appID int, viewerID *int) ([]model.ApplicationTimelineEntry, error) {
+	var app model.Application
+	if err := config.DB.First(&app, "application_id = ?", appID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("报名记录不存在")
+		}
+		return nil, errors.New("查询报名记录失败")
 	}
 
-	log := model.ApplicationStatusLog{
-		ApplicationID: appID,
-		HandlerID:     &handlerID,
-		LogStatus:     status,
-		HandleTime:    now,
+	var timeline []model.ApplicationTimelineEntry
+	if err := config.DB.Table("ApplicationStatusLog l").
+		Select("l.log_id, l.log_status, l.handler_id, COALESCE(u.username, '系统') AS handler_name, COALESCE(l.comment, '') AS comment, COALESCE(l.internal_note, '') AS internal_note, l.handle_time").
+		Joins("LEFT JOIN User u ON l.handler_id = u.user_id").
+		Where("l.application_id = ?", appID).
+		Order("l.handle_time ASC, l.log_id ASC").
+		Scan(&timeline).Error; err != nil {
+		return nil, errors.New("查询报名状态历史失败")
 	}
 
-	if err := config.DB.Create(&log).Error; err != nil {
-		return errors.New("保存审核日志失败")
+	admin := false
+	if viewerID != nil {
+		var err error
+		if admin, err = isAdmin(*viewerID); err != nil {
+			return nil, err
+		}
 	}
-
-	return nil
+	if !admin {
+		for i := range timeline {
+			timeline[i].InternalNote = ""
+		}
+	}
+	return timeline, nil
 }
 
-// CancelApplication 取消报名
-func CancelApplication(appID int) error {
+// CancelApplication 用户撤回报名：记录为撤回状态并保留历史，释放名额
+func CancelApplication(appID int, reason string) error {
+	reason, err := normalizeComment(reason, "撤回原因")
+	if err != nil {
+		return err
+	}
+
 	var app model.Application
 	if err := config.DB.First(&app, "application_id = ?", appID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -181,20 +407,7 @@ func CancelApplication(appID int) error {
 		return errors.New("活动已开始，不能取消报名")
 	}
 
-	// 只允许取消待审批和已批准的报名
-	if app.CurrentStatus != "pending" && app.CurrentStatus != "approved" {
-		return errors.New("该报名状态不允许取消")
-	}
-
-	// 先删除相关的状态日志（因为有外键约束）
-	if err := config.DB.Delete(&model.ApplicationStatusLog{}, "application_id = ?", appID).Error; err != nil {
-		return errors.New("删除状态日志失败")
-	}
-
-	// 再删除报名记录
-	if err := config.DB.Delete(&model.Application{}, "application_id = ?", appID).Error; err != nil {
-		return errors.New("取消报名失败")
-	}
-
-	return nil
+	return transaction(func(tx *gorm.DB) error {
+		return changeApplicationStatus(tx, &app, model.AppStatusWithdrawn, &app.UserID, reason)
+	})
 }
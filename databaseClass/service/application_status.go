@@ -0,0 +1,232 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"volunteer-system/mailer"
+	"volunteer-system/model"
+	"volunteer-system/realtime"
+
+	"gorm.io/gorm"
+)
+
+// applicationTransitions 报名状态允许的流转
+var applicationTransitions = map[string][]string{
+	model.AppStatusPending: {
+		model.AppStatusApproved,
+		model.AppStatusRejected,
+		model.AppStatusWaitlisted,
+		model.AppStatusWithdrawn,
+		model.AppStatusCancelledByOrganizer,
+		model.AppStatusExpired,
+	},
+	model.AppStatusWaitlisted: {
+		model.AppStatusApproved,
+		model.AppStatusRejected,
+		model.AppStatusWithdrawn,
+		model.AppStatusCancelledByOrganizer,
+		model.AppStatusExpired,
+	},
+	model.AppStatusApproved: {
+		model.AppStatusWithdrawn,
+		model.AppStatusCancelledByOrganizer,
+		model.AppStatusAttended,
+		model.AppStatusNoShow,
+	},
+	// 考勤结果允许管理员更正
+	model.AppStatusAttended: {model.AppStatusNoShow},
+	model.AppStatusNoShow:   {model.AppStatusAttended},
+	// 撤回后按策略允许重新报名
+	model.AppStatusWithdrawn: {model.AppStatusPending},
+	// 终态
+	model.AppStatusRejected:             {},
+	model.AppStatusCancelledByOrganizer: {},
+	model.AppStatusExpired:              {},
+}
+
+var applicationStatusNames = map[string]string{
+	model.AppStatusPending:              "待审核",
+	model.AppStatusApproved:             "已批准",
+	model.AppStatusRejected:             "已拒绝",
+	model.AppStatusWithdrawn:            "已撤回",
+	model.AppStatusCancelledByOrganizer: "组织者已取消",
+	model.AppStatusWaitlisted:           "候补",
+	model.AppStatusAttended:             "已参加",
+	model.AppStatusNoShow:               "未到场",
+	model.AppStatusExpired:              "已过期",
+}
+
+// releasedStatuses 已释放名额、不再参与的报名状态
+var releasedStatuses = []string{
+	model.AppStatusWithdrawn, model.AppStatusRejected, model.AppStatusCancelledByOrganizer, model.AppStatusExpired,
+}
+
+// activeApplicationStatuses 未释放的报名状态，报名时据此检查时间冲突
+var activeApplicationStatuses = []string{
+	model.AppStatusPending, model.AppStatusWaitlisted, model.AppStatusApproved,
+	model.AppStatusAttended, model.AppStatusNoShow,
+}
+
+// slotHoldingStatuses 占用活动名额的报名状态
+var slotHoldingStatuses = []string{model.AppStatusApproved, model.AppStatusAttended}
+
+func containsStatus(statuses []string, status string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func isReleasedStatus(status string) bool    { return containsStatus(releasedStatuses, status) }
+func isSlotHoldingStatus(status string) bool { return containsStatus(slotHoldingStatuses, status) }
+
+func isKnownApplicationStatus(status string) bool {
+	_, ok := applicationTransitions[status]
+	return ok
+}
+
+func canTransition(from, to string) bool {
+	for _, next := range applicationTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func statusName(status string) string {
+	if name, ok := applicationStatusNames[status]; ok {
+		return name
+	}
+	return status
+}
+
+// changeApplicationStatus 按状态机变更报名状态并写入状态日志，handlerID为nil表示系统处理。
+// 所有报名状态变更都应通过此函数完成。
+func changeApplicationStatus(tx *gorm.DB, app *model.Application, to string, handlerID *int, comment string) error {
+	return changeApplicationStatusWithNote(tx, app, to, handlerID, comment, "")
+}
+
+// changeApplicationStatusWithNote 同 changeApplicationStatus，另记录只给管理员看的系统说明
+func changeApplicationStatusWithNote(tx *gorm.DB, app *model.Application, to string, handlerID *int, comment, note string) error {
+	if !isKnownApplicationStatus(to) {
+		return &CodedError{Code: ErrCodeInvalidStatus, Message: "未知的报名状态：" + to}
+	}
+	if app.CurrentStatus == to {
+		return &CodedError{
+			Code:    ErrCodeStatusUnchanged,
+			Message: fmt.Sprintf("报名已是%s状态", statusName(to)),
+		}
+	}
+	if !canTransition(app.CurrentStatus, to) {
+		return &CodedError{
+			Code:    ErrCodeIllegalTransition,
+			Message: fmt.Sprintf("报名状态不能从%s变更为%s", statusName(app.CurrentStatus), statusName(to)),
+		}
+	}
+
+	// 以当前状态为条件更新，防止并发审核覆盖
+	result := tx.Model(&model.Application{}).
+		Where("application_id = ? AND current_status = ?", app.ApplicationID, app.CurrentStatus).
+		Update("current_status", to)
+	if result.Error != nil {
+		return errors.New("更新报名状态失败")
+	}
+	if result.RowsAffected == 0 {
+		return &CodedError{Code: ErrCodeStatusConflict, Message: "报名状态已被其他操作修改，请刷新后重试"}
+	}
+
+	log := model.ApplicationStatusLog{
+		ApplicationID: app.ApplicationID,
+		HandlerID:     handlerID,
+		LogStatus:     to,
+		HandleTime:    time.Now(),
+		Comment:       comment,
+		InternalNote:  note,
+	}
+	if err := tx.Create(&log).Error; err != nil {
+		return errors.New("保存审核日志失败")
+	}
+
+	from := app.CurrentStatus
+	app.CurrentStatus = to
+
+	publishEvent(tx, realtime.Event{
+		Type:   realtime.EventApplicationStatus,
+		UserID: app.UserID,
+		Admins: true,
+		Data:   applicationEventData(app),
+	})
+	if isReleasedStatus(from) != isReleasedStatus(to) || isSlotHoldingStatus(from) != isSlotHoldingStatus(to) {
+		if err := publishActivitySlots(tx, app.ActivityID); err != nil {
+			return err
+		}
+	}
+
+	if to == model.AppStatusApproved {
+		if err := publishWebhookEvent(tx, model.WebhookEventApplicationApproved, map[string]interface{}{
+			"application_id": app.ApplicationID,
+			"activity_id":    app.ActivityID,
+			"user_id":        app.UserID,
+			"status":         to,
+			"handler_id":     handlerID,
+			"comment":        comment,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 管理员或系统变更状态时通知报名者，报名者自己的操作不通知
+	if handlerID == nil || *handlerID != app.UserID {
+		return notifyStatusChange(tx, app, comment)
+	}
+	return nil
+}
+
+// notifyStatusChange 通知报名者报名状态已变更，批准和拒绝时同时发送邮件
+func notifyStatusChange(tx *gorm.DB, app *model.Application, comment string) error {
+	var activity model.Activity
+	if err := tx.Select("activity_id", "title", "activity_time", "location").
+		First(&activity, "activity_id = ?", app.ActivityID).Error; err != nil {
+		return errors.New("查询活动失败")
+	}
+
+	content := fmt.Sprintf("您报名的活动“%s”状态已变更为%s", activity.Title, statusName(app.CurrentStatus))
+	if comment != "" {
+		content += "：" + comment
+	}
+	if err := notifyUser(tx, app.UserID, "报名状态更新", content, &app.ActivityID, &app.ApplicationID); err != nil {
+		return err
+	}
+
+	templateName := ""
+	switch app.CurrentStatus {
+	case model.AppStatusApproved:
+		templateName = mailer.TemplateApplicationApproved
+	case model.AppStatusRejected:
+		templateName = mailer.TemplateApplicationRejected
+	default:
+		return nil
+	}
+	return enqueueEmail(tx, app.UserID, templateName, map[string]interface{}{
+		"ActivityTitle": activity.Title,
+		"ActivityTime":  activity.ActivityTime.Format("2006-01-02 15:04"),
+		"Location":      activity.Location,
+		"Comment":       comment,
+	})
+}
+
+// countSlotHolders 统计活动已占用的名额
+func countSlotHolders(tx *gorm.DB, activityID int) (int64, error) {
+	var count int64
+	if err := tx.Model(&model.Application{}).
+		Where("activity_id = ? AND current_status IN ?", activityID, slotHoldingStatuses).
+		Count(&count).Error; err != nil {
+		return 0, errors.New("查询活动报名人数失败")
+	}
+	return count, nil
+}
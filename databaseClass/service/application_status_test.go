@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+
+	"volunteer-system/model"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     bool
+	}{
+		{"待审核可批准", model.AppStatusPending, model.AppStatusApproved, true},
+		{"待审核可转候补", model.AppStatusPending, model.AppStatusWaitlisted, true},
+		{"待审核可过期", model.AppStatusPending, model.AppStatusExpired, true},
+		{"待审核不能直接签到", model.AppStatusPending, model.AppStatusAttended, false},
+		{"候补可批准", model.AppStatusWaitlisted, model.AppStatusApproved, true},
+		{"候补不能回到待审核", model.AppStatusWaitlisted, model.AppStatusPending, false},
+		{"已批准可撤回", model.AppStatusApproved, model.AppStatusWithdrawn, true},
+		{"已批准可记为未到场", model.AppStatusApproved, model.AppStatusNoShow, true},
+		{"已批准不能拒绝", model.AppStatusApproved, model.AppStatusRejected, false},
+		{"已批准不能过期", model.AppStatusApproved, model.AppStatusExpired, false},
+		{"考勤结果可更正为未到场", model.AppStatusAttended, model.AppStatusNoShow, true},
+		{"考勤结果可更正为已参加", model.AppStatusNoShow, model.AppStatusAttended, true},
+		{"已参加不能撤回", model.AppStatusAttended, model.AppStatusWithdrawn, false},
+		{"撤回后可重新报名", model.AppStatusWithdrawn, model.AppStatusPending, true},
+		{"撤回后不能直接批准", model.AppStatusWithdrawn, model.AppStatusApproved, false},
+		{"已拒绝是终态", model.AppStatusRejected, model.AppStatusPending, false},
+		{"组织者取消是终态", model.AppStatusCancelledByOrganizer, model.AppStatusApproved, false},
+		{"已过期是终态", model.AppStatusExpired, model.AppStatusPending, false},
+		{"相同状态不算流转", model.AppStatusPending, model.AppStatusPending, false},
+		{"未知的原状态", "unknown", model.AppStatusApproved, false},
+		{"未知的目标状态", model.AppStatusPending, "unknown", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canTransition(tt.from, tt.to); got != tt.want {
+				t.Errorf("canTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+// 流转目标都是已知状态，且不会流转到自身
+func TestApplicationTransitionsTargetsKnown(t *testing.T) {
+	for from, targets := range applicationTransitions {
+		for _, to := range targets {
+			if !isKnownApplicationStatus(to) {
+				t.Errorf("%s 可流转到未知状态 %s", from, to)
+			}
+			if from == to {
+				t.Errorf("%s 不应流转到自身", from)
+			}
+		}
+	}
+}
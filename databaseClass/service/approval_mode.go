@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"volunteer-system/model"
+	"volunteer-system/utils"
+)
+
+// normalizeApprovalMode 校验活动的审核方式及自动批准条件，不填默认人工审核
+func normalizeApprovalMode(mode string, rules model.EligibilityRules) (string, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		mode = model.ApprovalModeManual
+	}
+
+	switch mode {
+	case model.ApprovalModeManual, model.ApprovalModeAutoUntilFull, model.ApprovalModeLottery:
+		if len(rules) > 0 {
+			return "", errors.New("只有 auto_criteria 审核方式才能设置自动批准条件")
+		}
+	case model.ApprovalModeAutoCriteria:
+		if len(rules) == 0 {
+			return "", errors.New("auto_criteria 审核方式必须设置至少一条自动批准条件")
+		}
+		if err := validateEligibilityRules(rules); err != nil {
+			return "", err
+		}
+	default:
+		return "", errors.New("审核方式只能是 manual / auto_until_full / auto_criteria / lottery")
+	}
+	return mode, nil
+}
+
+// parseRegistrationDeadline 解析报名截止时间；抽签模式必须设置，且须早于活动开始时间
+func parseRegistrationDeadline(raw string, activityTime time.Time, mode string) (*time.Time, error) {
+	if strings.TrimSpace(raw) == "" {
+		if mode == model.ApprovalModeLottery {
+			return nil, errors.New("抽签模式必须设置报名截止时间")
+		}
+		return nil, nil
+	}
+
+	deadline, err := utils.ParseActivityTime(raw)
+	if err != nil {
+		return nil, errors.New("报名截止时间格式不正确")
+	}
+	if deadline.After(activityTime) {
+		return nil, errors.New("报名截止时间不能晚于活动开始时间")
+	}
+	return &deadline, nil
+}
+
+// autoApproval 判断报名是否自动批准，返回写入状态日志的说明
+func autoApproval(userID int, activity *model.Activity) (bool, string, error) {
+	switch activity.ApprovalMode {
+	case model.ApprovalModeAutoUntilFull:
+		return true, "先到先得，名额未满自动批准", nil
+	case model.ApprovalModeAutoCriteria:
+		profile, err := loadVolunteerProfile(userID)
+		if err != nil {
+			return false, "", err
+		}
+		if _, failed := profile.check(activity.AutoApproveRules); failed != nil {
+			return false, "", nil
+		}
+		return true, "满足自动批准条件，自动批准", nil
+	default:
+		return false, "", nil
+	}
+}
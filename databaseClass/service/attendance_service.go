@@ -0,0 +1,383 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/model"
+	"volunteer-system/utils"
+
+	"gorm.io/gorm"
+)
+
+const checkInCodeLength = 6
+
+// qrPayloadPrefix 二维码内容格式：volunteer-checkin:<活动ID>:<用途>:<签到码>
+const qrPayloadPrefix = "volunteer-checkin"
+
+// GenerateCheckInCode 组织者生成新的签到/签退码，同一活动同一用途的旧码立即失效
+func GenerateCheckInCode(activityID int, req *model.GenerateCheckInCodeRequest) (*model.CheckInCodeInfo, error) {
+	if err := requireAdmin(req.HandlerID, "生成签到码"); err != nil {
+		return nil, err
+	}
+	purpose := req.Purpose
+	if purpose == "" {
+		purpose = model.CheckInPurposeIn
+	}
+	if purpose != model.CheckInPurposeIn && purpose != model.CheckInPurposeOut {
+		return nil, errors.New("签到码用途只能是 check_in / check_out")
+	}
+
+	var activity model.Activity
+	if err := config.DB.First(&activity, "activity_id = ?", activityID).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+
+	code, err := utils.RandomCode(checkInCodeLength)
+	if err != nil {
+		return nil, errors.New("生成签到码失败")
+	}
+
+	now := time.Now()
+	record := model.CheckInCode{
+		ActivityID: activityID,
+		Code:       code,
+		Purpose:    purpose,
+		CreatedBy:  req.HandlerID,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(time.Duration(config.Policy.CheckInCodeTTLMinutes) * time.Minute),
+	}
+
+	err = transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.CheckInCode{}).
+			Where("activity_id = ? AND purpose = ? AND expires_at > ?", activityID, purpose, now).
+			Update("expires_at", now).Error; err != nil {
+			return errors.New("作废旧签到码失败")
+		}
+		if err := tx.Create(&record).Error; err != nil {
+			return errors.New("保存签到码失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CheckInCodeInfo{
+		Code:      record.Code,
+		Purpose:   record.Purpose,
+		ExpiresAt: record.ExpiresAt,
+		QRPayload: fmt.Sprintf("%s:%d:%s:%s", qrPayloadPrefix, activityID, purpose, code),
+	}, nil
+}
+
+// parseSubmittedCode 兼容直接输入签到码和扫描二维码得到的完整内容
+func parseSubmittedCode(activityID int, purpose, submitted string) (string, error) {
+	submitted = strings.ToUpper(strings.TrimSpace(submitted))
+	parts := strings.Split(submitted, ":")
+	if len(parts) == 1 {
+		return submitted, nil
+	}
+	if len(parts) != 4 || parts[0] != strings.ToUpper(qrPayloadPrefix) {
+		return "", errors.New("二维码内容无效")
+	}
+	if parts[1] != fmt.Sprintf("%d", activityID) {
+		return "", errors.New("该二维码不属于此活动")
+	}
+	if parts[2] != strings.ToUpper(purpose) {
+		if purpose == model.CheckInPurposeIn {
+			return "", errors.New("这是签退码，请使用签到码")
+		}
+		return "", errors.New("这是签到码，请使用签退码")
+	}
+	return parts[3], nil
+}
+
+func findValidCheckInCode(tx *gorm.DB, activityID int, purpose, submitted string) (*model.CheckInCode, error) {
+	code, err := parseSubmittedCode(activityID, purpose, submitted)
+	if err != nil {
+		return nil, err
+	}
+
+	var record model.CheckInCode
+	if err := tx.Where("activity_id = ? AND purpose = ? AND code = ?", activityID, purpose, code).
+		Order("code_id DESC").
+		First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("签到码不正确")
+		}
+		return nil, errors.New("查询签到码失败")
+	}
+	if !record.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("签到码已过期，请扫描最新的签到码")
+	}
+	return &record, nil
+}
+
+func findUserApplication(tx *gorm.DB, userID, activityID int) (*model.Application, error) {
+	var app model.Application
+	if err := tx.Where("user_id = ? AND activity_id = ?", userID, activityID).First(&app).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("您没有报名该活动")
+		}
+		return nil, errors.New("查询报名记录失败")
+	}
+	return &app, nil
+}
+
+func findAttendance(tx *gorm.DB, appID int) (*model.Attendance, error) {
+	var attendance model.Attendance
+	err := tx.Where("application_id = ?", appID).First(&attendance).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("查询考勤记录失败")
+	}
+	return &attendance, nil
+}
+
+func setAttendanceStatus(tx *gorm.DB, app *model.Application, status string) error {
+	if err := tx.Model(&model.Application{}).
+		Where("application_id = ?", app.ApplicationID).
+		Update("attendance_status", status).Error; err != nil {
+		return errors.New("更新考勤状态失败")
+	}
+	app.AttendanceStatus = status
+	return nil
+}
+
+// CheckIn 志愿者提交签到码签到，报名状态变为已参加
+func CheckIn(activityID, userID int, submitted string) (*model.Attendance, error) {
+	var activity model.Activity
+	if err := config.DB.First(&activity, "activity_id = ?", activityID).Error; err != nil {
+		return nil, errors.New("活动不存在")
+	}
+
+	openAt := activity.ActivityTime.Add(-time.Duration(config.Policy.CheckInOpenMinutes) * time.Minute)
+	if time.Now().Before(openAt) {
+		return nil, fmt.Errorf("签到将于 %s 开放", openAt.Format("2006-01-02 15:04"))
+	}
+
+	var attendance *model.Attendance
+	err := transaction(func(tx *gorm.DB) error {
+		code, err := findValidCheckInCode(tx, activityID, model.CheckInPurposeIn, submitted)
+		if err != nil {
+			return err
+		}
+
+		app, err := findUserApplication(tx, userID, activityID)
+		if err != nil {
+			return err
+		}
+		if app.CurrentStatus == model.AppStatusAttended {
+			return errors.New("您已签到")
+		}
+		if app.CurrentStatus != model.AppStatusApproved {
+			return fmt.Errorf("报名状态为%s，不能签到", statusName(app.CurrentStatus))
+		}
+
+		now := time.Now()
+		attendance, err = findAttendance(tx, app.ApplicationID)
+		if err != nil {
+			return err
+		}
+		if attendance == nil {
+			attendance = &model.Attendance{
+				ApplicationID: app.ApplicationID,
+				ActivityID:    activityID,
+				UserID:        userID,
+			}
+		}
+		attendance.CheckInTime = &now
+		attendance.CheckOutTime = nil
+		attendance.CheckInCodeID = &code.CodeID
+		attendance.Method = "code"
+		attendance.MarkedBy = nil
+		attendance.UpdatedAt = now
+		if err := tx.Save(attendance).Error; err != nil {
+			return errors.New("保存签到记录失败")
+		}
+
+		if err := setAttendanceStatus(tx, app, model.AttendanceCheckedIn); err != nil {
+			return err
+		}
+		// 服务时长在签退时按实际时长记录，未签退的在活动结束后按活动时长记录
+		return changeApplicationStatus(tx, app, model.AppStatusAttended, &userID, "扫码签到")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attendance, nil
+}
+
+// CheckOut 志愿者提交签退码签退
+func CheckOut(activityID, userID int, submitted string) (*model.Attendance, error) {
+	var attendance *model.Attendance
+	err := transaction(func(tx *gorm.DB) error {
+		if _, err := findValidCheckInCode(tx, activityID, model.CheckInPurposeOut, submitted); err != nil {
+			return err
+		}
+
+		app, err := findUserApplication(tx, userID, activityID)
+		if err != nil {
+			return err
+		}
+
+		attendance, err = findAttendance(tx, app.ApplicationID)
+		if err != nil {
+			return err
+		}
+		if attendance == nil || attendance.CheckInTime == nil {
+			return errors.New("您还没有签到")
+		}
+		if attendance.CheckOutTime != nil {
+			return errors.New("您已签退")
+		}
+
+		now := time.Now()
+		attendance.CheckOutTime = &now
+		attendance.UpdatedAt = now
+		if err := tx.Save(attendance).Error; err != nil {
+			return errors.New("保存签退记录失败")
+		}
+
+		if err := setAttendanceStatus(tx, app, model.AttendanceCheckedOut); err != nil {
+			return err
+		}
+		return recordAttendanceHours(tx, app, attendance)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attendance, nil
+}
+
+func parseOptionalTime(raw string) (*time.Time, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	t, err := utils.ParseActivityTime(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// MarkAttendance 管理员手动登记到场或缺席
+func MarkAttendance(appID int, req *model.MarkAttendanceRequest) (*model.Attendance, error) {
+	if err := requireAdmin(req.HandlerID, "登记考勤"); err != nil {
+		return nil, err
+	}
+	status := strings.ToLower(strings.TrimSpace(req.Status))
+	if status != "present" && status != "absent" {
+		return nil, errors.New("考勤状态只能是 present / absent")
+	}
+
+	checkIn, err := parseOptionalTime(req.CheckInTime)
+	if err != nil {
+		return nil, errors.New("签到时间格式不正确")
+	}
+	checkOut, err := parseOptionalTime(req.CheckOutTime)
+	if err != nil {
+		return nil, errors.New("签退时间格式不正确")
+	}
+	if checkOut != nil && (checkIn == nil || checkOut.Before(*checkIn)) {
+		return nil, errors.New("签退时间必须晚于签到时间")
+	}
+
+	var attendance *model.Attendance
+	err = transaction(func(tx *gorm.DB) error {
+		var app model.Application
+		if err := tx.First(&app, "application_id = ?", appID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("报名记录不存在")
+			}
+			return errors.New("查询报名记录失败")
+		}
+
+		attendance, err = findAttendance(tx, appID)
+		if err != nil {
+			return err
+		}
+		if attendance == nil {
+			attendance = &model.Attendance{
+				ApplicationID: app.ApplicationID,
+				ActivityID:    app.ActivityID,
+				UserID:        app.UserID,
+			}
+		}
+
+		target := model.AppStatusAttended
+		comment := "管理员登记到场"
+		if status == "absent" {
+			target = model.AppStatusNoShow
+			comment = "管理员登记缺席"
+			attendance.CheckInTime, attendance.CheckOutTime = nil, nil
+		} else if checkIn != nil {
+			// 未提供时间时保留扫码签到的记录
+			attendance.CheckInTime, attendance.CheckOutTime = checkIn, checkOut
+		}
+
+		attendanceStatus := model.AttendanceAbsent
+		if status == "present" {
+			switch {
+			case attendance.CheckOutTime != nil:
+				attendanceStatus = model.AttendanceCheckedOut
+			case attendance.CheckInTime != nil:
+				attendanceStatus = model.AttendanceCheckedIn
+			default:
+				attendanceStatus = model.AttendanceConfirmed
+			}
+		}
+
+		attendance.Method = "manual"
+		attendance.MarkedBy = &req.HandlerID
+		attendance.UpdatedAt = time.Now()
+		if err := tx.Save(attendance).Error; err != nil {
+			return errors.New("保存考勤记录失败")
+		}
+
+		if err := setAttendanceStatus(tx, &app, attendanceStatus); err != nil {
+			return err
+		}
+		// 已是目标状态时只更新考勤时间
+		if app.CurrentStatus != target {
+			if err := changeApplicationStatus(tx, &app, target, &req.HandlerID, comment); err != nil {
+				return err
+			}
+		}
+
+		if status == "absent" {
+			if err := removeAttendanceHours(tx, app.ApplicationID); err != nil {
+				return err
+			}
+			return evaluateNoShowPenalty(tx, app.UserID)
+		}
+		return recordAttendanceHours(tx, &app, attendance)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attendance, nil
+}
+
+// ListActivityAttendance 活动考勤名单（已批准及已有考勤结果的报名）
+func ListActivityAttendance(activityID int) ([]model.ActivityAttendanceInfo, error) {
+	var list []model.ActivityAttendanceInfo
+	if err := config.DB.Table("Application app").
+		Select(`app.application_id, app.user_id, u.username, app.current_status, app.attendance_status,
+			att.check_in_time, att.check_out_time, COALESCE(att.method, '') AS method`).
+		Joins("JOIN User u ON app.user_id = u.user_id").
+		Joins("LEFT JOIN Attendance att ON app.application_id = att.application_id").
+		Where("app.activity_id = ? AND app.current_status IN ?", activityID,
+			[]string{model.AppStatusApproved, model.AppStatusAttended, model.AppStatusNoShow}).
+		Order("u.username ASC").
+		Scan(&list).Error; err != nil {
+		return nil, errors.New("查询考勤名单失败")
+	}
+	return list, nil
+}
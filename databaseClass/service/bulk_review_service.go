@@ -0,0 +1,182 @@
+package service
+
+import (
+	"errors"
+	"sort"
+
+	"volunteer-system/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxBulkReviewSize 单次批量审核的报名数上限
+const maxBulkReviewSize = 500
+
+// BulkReviewApplications 批量批准或拒绝报名。
+// 所有报名在同一事务中处理，单条失败（状态不允许、名额已满等）只回滚该条并记入结果，不影响其余报名。
+func BulkReviewApplications(req *model.BulkReviewRequest) (*model.BulkReviewResult, error) {
+	status, comment, err := normalizeReview(req.Status, req.Comment)
+	if err != nil {
+		return nil, err
+	}
+	if status != model.AppStatusApproved && status != model.AppStatusRejected {
+		return nil, &CodedError{Code: ErrCodeInvalidStatus, Message: "批量审核只支持 approved / rejected"}
+	}
+	if err := requireConflictOverride(req.OverrideConflict, &req.HandlerID); err != nil {
+		return nil, err
+	}
+
+	byActivity := req.ActivityID != nil
+	if byActivity == (len(req.ApplicationIDs) > 0) {
+		return nil, errors.New("请指定报名ID列表，或指定活动及批准人数，二者只能选一")
+	}
+	if byActivity {
+		if status != model.AppStatusApproved {
+			return nil, errors.New("按报名顺序审核只支持批准")
+		}
+		if req.FirstN <= 0 {
+			return nil, errors.New("批准人数必须大于0")
+		}
+	}
+
+	result := &model.BulkReviewResult{}
+	err = transaction(func(tx *gorm.DB) error {
+		ids := req.ApplicationIDs
+		if byActivity {
+			// 先锁定活动，再按报名顺序选取待审核报名，避免与其他审核并发选中同一批
+			var activity model.Activity
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				First(&activity, "activity_id = ?", *req.ActivityID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.New("活动不存在")
+				}
+				return errors.New("查询活动信息失败")
+			}
+			ids = nil
+			if err := tx.Model(&model.Application{}).
+				Where("activity_id = ? AND current_status = ?", activity.ActivityID, model.AppStatusPending).
+				Order("apply_time ASC, application_id ASC").
+				Limit(req.FirstN).
+				Pluck("application_id", &ids).Error; err != nil {
+				return errors.New("查询待审核报名失败")
+			}
+		}
+		ids = uniqueInts(ids)
+		if len(ids) > maxBulkReviewSize {
+			return errors.New("单次批量审核不能超过500条报名")
+		}
+
+		var apps []model.Application
+		if len(ids) > 0 {
+			if err := tx.Where("application_id IN ?", ids).Find(&apps).Error; err != nil {
+				return errors.New("查询报名记录失败")
+			}
+		}
+		appByID := make(map[int]*model.Application, len(apps))
+		for i := range apps {
+			appByID[apps[i].ApplicationID] = &apps[i]
+		}
+
+		remaining := map[int]int{}
+		activities := map[int]*model.Activity{}
+		if status == model.AppStatusApproved {
+			var err error
+			if activities, remaining, err = lockRemainingSlots(tx, apps); err != nil {
+				return err
+			}
+		}
+
+		for _, id := range ids {
+			item := model.BulkReviewItemResult{ApplicationID: id}
+			app, ok := appByID[id]
+			switch {
+			case !ok:
+				item.Message = "报名记录不存在"
+			case status == model.AppStatusApproved && app.TeamApplicationID != nil:
+				item.Message = errTeamApproval.Error()
+			case status == model.AppStatusApproved && remaining[app.ActivityID] <= 0:
+				item.Message = "活动人数已满，无法再通过报名"
+			default:
+				// 嵌套事务对应保存点，单条失败只回滚该条
+				err := savepoint(tx, func(itx *gorm.DB) error {
+					note := ""
+					if status == model.AppStatusApproved {
+						// 同批中先批准的报名也参与冲突检查
+						var err error
+						note, err = checkTimeConflict(itx, app.UserID, activities[app.ActivityID], slotHoldingStatuses, req.OverrideConflict)
+						if err != nil {
+							return err
+						}
+					}
+					return changeApplicationStatusWithNote(itx, app, status, &req.HandlerID, comment, note)
+				})
+				if err != nil {
+					item.Message = err.Error()
+					var codedErr *CodedError
+					if errors.As(err, &codedErr) {
+						item.Code = codedErr.Code
+					}
+				} else {
+					item.Success = true
+					if status == model.AppStatusApproved {
+						remaining[app.ActivityID]--
+					}
+				}
+			}
+
+			if item.Success {
+				result.Succeeded++
+			} else {
+				result.Failed++
+			}
+			result.Items = append(result.Items, item)
+		}
+		result.Total = len(result.Items)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// lockRemainingSlots 按活动ID顺序锁定涉及的活动，返回活动信息及各活动的剩余名额
+func lockRemainingSlots(tx *gorm.DB, apps []model.Application) (map[int]*model.Activity, map[int]int, error) {
+	activityIDs := make([]int, 0, len(apps))
+	for _, app := range apps {
+		activityIDs = append(activityIDs, app.ActivityID)
+	}
+	activityIDs = uniqueInts(activityIDs)
+	sort.Ints(activityIDs)
+
+	activities := make(map[int]*model.Activity, len(activityIDs))
+	remaining := make(map[int]int, len(activityIDs))
+	for _, activityID := range activityIDs {
+		var activity model.Activity
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&activity, "activity_id = ?", activityID).Error; err != nil {
+			return nil, nil, errors.New("查询活动信息失败")
+		}
+		holders, err := countSlotHolders(tx, activityID)
+		if err != nil {
+			return nil, nil, err
+		}
+		activities[activityID] = &activity
+		remaining[activityID] = activity.MaxPeople - int(holders)
+	}
+	return activities, remaining, nil
+}
+
+// uniqueInts 去重并保持原有顺序
+func uniqueInts(values []int) []int {
+	seen := make(map[int]bool, len(values))
+	result := make([]int, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
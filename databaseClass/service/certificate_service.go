@@ -0,0 +1,308 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/model"
+	"volunteer-system/utils"
+
+	"gorm.io/gorm"
+)
+
+const verificationCodeLength = 12
+
+// certificateIssuer 证明文件上的签发单位
+const certificateIssuer = "志愿者活动管理系统"
+
+// attendedActivityRecord 已参加的活动及该次服务时长
+type attendedActivityRecord struct {
+	ApplicationID int
+	UserID        int
+	Username      string
+	ActivityID    int
+	Title         string
+	ActivityTime  time.Time
+	Location      string
+	DeptName      string
+	CategoryName  string
+	Hours         float64
+}
+
+// queryAttendedActivities 查询已参加的活动（5表JOIN + 关联子查询计算时长）
+func queryAttendedActivities(condition string, args ...interface{}) ([]attendedActivityRecord, error) {
+	var records []attendedActivityRecord
+	err := config.DB.Raw(`
+		SELECT app.application_id, app.user_id, u.username,
+			a.activity_id, a.title, a.activity_time, a.location,
+			COALESCE(d.dept_name, '') as dept_name,
+			COALESCE(ac.category_name, '') as category_name,
+			COALESCE((SELECT SUM(h.hours) FROM ServiceHoursEntry h
+				WHERE h.application_id = app.application_id), 0) as hours
+		FROM Application app
+		JOIN User u ON app.user_id = u.user_id
+		JOIN Activity a ON app.activity_id = a.activity_id
+		LEFT JOIN Dept d ON a.dept_id = d.dept_id
+		LEFT JOIN ActivityCategory ac ON a.category_id = ac.category_id
+		WHERE app.current_status = 'attended' AND `+condition+`
+		ORDER BY a.activity_time ASC
+	`, args...).Scan(&records).Error
+	if err != nil {
+		return nil, errors.New("查询服务记录失败")
+	}
+	return records, nil
+}
+
+// issueCertificate 签发证明文件；内容（活动数、时长）未变化时沿用上次的验证码
+func issueCertificate(certType string, userID int, appID *int, activityCount int, totalHours float64) (*model.Certificate, error) {
+	var latest model.Certificate
+	query := config.DB.Where("type = ? AND user_id = ?", certType, userID)
+	if appID != nil {
+		query = query.Where("application_id = ?", *appID)
+	}
+	err := query.Order("issued_at DESC, certificate_id DESC").First(&latest).Error
+	if err == nil && latest.ActivityCount == activityCount && latest.TotalHours == totalHours {
+		return &latest, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("查询证明记录失败")
+	}
+
+	code, err := utils.RandomCode(verificationCodeLength)
+	if err != nil {
+		return nil, errors.New("生成验证码失败")
+	}
+
+	cert := model.Certificate{
+		VerificationCode: code,
+		Type:             certType,
+		UserID:           userID,
+		ApplicationID:    appID,
+		ActivityCount:    activityCount,
+		TotalHours:       totalHours,
+		IssuedAt:         time.Now(),
+	}
+	if err := config.DB.Create(&cert).Error; err != nil {
+		return nil, errors.New("保存证明记录失败")
+	}
+	return &cert, nil
+}
+
+// GenerateActivityCertificate 为一次已参加的活动生成服务证明PDF
+func GenerateActivityCertificate(appID int) ([]byte, *model.Certificate, error) {
+	records, err := queryAttendedActivities("app.application_id = ?", appID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		var app model.Application
+		if err := config.DB.First(&app, "application_id = ?", appID).Error; err != nil {
+			return nil, nil, errors.New("报名记录不存在")
+		}
+		return nil, nil, errors.New("只有已参加的活动才能开具服务证明")
+	}
+	record := records[0]
+
+	cert, err := issueCertificate(model.CertificateTypeActivity, record.UserID, &record.ApplicationID, 1, roundHours(record.Hours))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return renderActivityCertificate(&record, cert), cert, nil
+}
+
+// GenerateTranscript 生成用户的服务记录成绩单PDF
+func GenerateTranscript(userID int) ([]byte, *model.Certificate, error) {
+	var user model.User
+	if err := config.DB.First(&user, "user_id = ?", userID).Error; err != nil {
+		return nil, nil, errors.New("用户不存在")
+	}
+
+	records, err := queryAttendedActivities("app.user_id = ?", userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 合计时长包含未关联活动的调整记录
+	var totalHours float64
+	if err := config.DB.Model(&model.ServiceHoursEntry{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(SUM(hours), 0)").
+		Scan(&totalHours).Error; err != nil {
+		return nil, nil, errors.New("查询服务时长失败")
+	}
+
+	cert, err := issueCertificate(model.CertificateTypeTranscript, userID, nil, len(records), roundHours(totalHours))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return renderTranscript(user.Username, records, cert), cert, nil
+}
+
+// VerifyCertificate 凭验证码查验证明文件
+func VerifyCertificate(code string) (*model.CertificateVerification, error) {
+	code = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+
+	var cert model.Certificate
+	if err := config.DB.Where("verification_code = ?", code).First(&cert).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.CertificateVerification{Valid: false, VerificationCode: code}, nil
+		}
+		return nil, errors.New("查询证明记录失败")
+	}
+
+	result := &model.CertificateVerification{
+		Valid:            true,
+		VerificationCode: formatVerificationCode(cert.VerificationCode),
+		Type:             cert.Type,
+		ActivityCount:    cert.ActivityCount,
+		TotalHours:       cert.TotalHours,
+		IssuedAt:         cert.IssuedAt,
+	}
+
+	var user model.User
+	if err := config.DB.First(&user, "user_id = ?", cert.UserID).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("查询用户信息失败")
+		}
+	} else {
+		result.Username = user.Username
+	}
+
+	if cert.ApplicationID != nil {
+		var title string
+		if err := config.DB.Table("Application app").
+			Select("a.title").
+			Joins("JOIN Activity a ON app.activity_id = a.activity_id").
+			Where("app.application_id = ?", *cert.ApplicationID).
+			Scan(&title).Error; err != nil {
+			return nil, errors.New("查询活动信息失败")
+		}
+		result.ActivityTitle = title
+	}
+
+	return result, nil
+}
+
+// formatVerificationCode 每4位加一个短横线，便于阅读和手工输入
+func formatVerificationCode(code string) string {
+	var parts []string
+	for i := 0; i < len(code); i += 4 {
+		end := i + 4
+		if end > len(code) {
+			end = len(code)
+		}
+		parts = append(parts, code[i:end])
+	}
+	return strings.Join(parts, "-")
+}
+
+func formatHours(hours float64) string {
+	return strconv.FormatFloat(roundHours(hours), 'f', -1, 64)
+}
+
+// truncateText 截断超出宽度的文字
+func truncateText(text string, size, width float64) string {
+	if utils.TextWidth(text, size) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && utils.TextWidth(string(runes)+"…", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+func drawVerificationFooter(doc *utils.PDFDocument, cert *model.Certificate) {
+	code := formatVerificationCode(cert.VerificationCode)
+	doc.Line(60, 110, utils.PDFPageWidth-60, 110, 0.5)
+	doc.Text(60, 90, 10, "签发单位："+certificateIssuer)
+	doc.Text(60, 74, 10, "签发日期："+cert.IssuedAt.Format("2006年01月02日"))
+	doc.Text(60, 58, 10, "验证码："+code)
+	doc.Text(60, 42, 10, "查验地址：/certificates/verify/"+code)
+}
+
+func renderActivityCertificate(record *attendedActivityRecord, cert *model.Certificate) []byte {
+	doc := utils.NewPDFDocument()
+	doc.Rect(40, 40, utils.PDFPageWidth-80, utils.PDFPageHeight-80, 1.5)
+	doc.TextCenter(700, 30, "志愿服务证明")
+	doc.Line(150, 685, utils.PDFPageWidth-150, 685, 1)
+
+	organizer := record.DeptName
+	if organizer == "" {
+		organizer = certificateIssuer
+	}
+	activityDesc := "“" + record.Title + "”志愿服务活动"
+	if record.CategoryName != "" {
+		activityDesc += "（" + record.CategoryName + "）"
+	}
+
+	y := doc.Paragraph(80, 620, utils.PDFPageWidth-160, 15, 30, fmt.Sprintf(
+		"    兹证明 %s 于 %s 参加了由%s组织的%s，服务地点：%s，服务时长 %s 小时。",
+		record.Username,
+		record.ActivityTime.Format("2006年01月02日"),
+		organizer,
+		activityDesc,
+		record.Location,
+		formatHours(record.Hours),
+	))
+	doc.Paragraph(80, y-10, utils.PDFPageWidth-160, 15, 30, "    特此证明。")
+
+	drawVerificationFooter(doc, cert)
+	return doc.Bytes()
+}
+
+func renderTranscript(username string, records []attendedActivityRecord, cert *model.Certificate) []byte {
+	doc := utils.NewPDFDocument()
+
+	const (
+		colIndex = 60.0
+		colTitle = 95.0
+		colTime  = 300.0
+		colDept  = 400.0
+		colHours = 490.0
+		rowSize  = 10.0
+		rowStep  = 20.0
+		minY     = 130.0
+	)
+
+	drawHeader := func(y float64) float64 {
+		doc.Text(colIndex, y, rowSize, "序号")
+		doc.Text(colTitle, y, rowSize, "活动名称")
+		doc.Text(colTime, y, rowSize, "活动时间")
+		doc.Text(colDept, y, rowSize, "组织部门")
+		doc.Text(colHours, y, rowSize, "时长(小时)")
+		doc.Line(colIndex, y-6, utils.PDFPageWidth-60, y-6, 0.5)
+		return y - rowStep
+	}
+
+	doc.TextCenter(770, 24, "志愿服务记录")
+	doc.Text(60, 730, 12, fmt.Sprintf("姓名：%s    参加活动：%d 次    累计服务时长：%s 小时",
+		username, cert.ActivityCount, formatHours(cert.TotalHours)))
+
+	y := drawHeader(700)
+	if len(records) == 0 {
+		doc.Text(colTitle, y, rowSize, "暂无已参加的志愿活动")
+	}
+	for i, record := range records {
+		if y < minY {
+			drawVerificationFooter(doc, cert)
+			doc.AddPage()
+			y = drawHeader(780)
+		}
+		doc.Text(colIndex, y, rowSize, strconv.Itoa(i+1))
+		doc.Text(colTitle, y, rowSize, truncateText(record.Title, rowSize, colTime-colTitle-10))
+		doc.Text(colTime, y, rowSize, record.ActivityTime.Format("2006-01-02 15:04"))
+		doc.Text(colDept, y, rowSize, truncateText(record.DeptName, rowSize, colHours-colDept-10))
+		doc.Text(colHours, y, rowSize, formatHours(record.Hours))
+		y -= rowStep
+	}
+
+	drawVerificationFooter(doc, cert)
+	return doc.Bytes()
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"volunteer-system/model"
+
+	"gorm.io/gorm"
+)
+
+// activityConflictCondition 活动a1与a2开始时间相差不足2小时视为时间冲突，
+// 可申请活动列表、报名和批准共用这一判断
+const activityConflictCondition = "ABS(HOUR(TIMEDIFF(a1.activity_time, a2.activity_time))) < 2"
+
+// findConflictingActivity 查找用户已报名（报名状态属于statuses）且与activity时间冲突的其他活动，没有则返回nil
+func findConflictingActivity(tx *gorm.DB, userID int, activity *model.Activity, statuses []string) (*model.Activity, error) {
+	var conflicts []model.Activity
+	if err := tx.Raw(`
+		SELECT a2.*
+		FROM Application app
+		JOIN Activity a2 ON app.activity_id = a2.activity_id
+		JOIN Activity a1 ON a1.activity_id = ?
+		WHERE app.user_id = ? AND app.current_status IN ?
+		AND a2.activity_id <> a1.activity_id AND a2.status IN ('active', 'registration_closed')
+		AND `+activityConflictCondition+`
+		ORDER BY a2.activity_time ASC
+		LIMIT 1
+	`, activity.ActivityID, userID, statuses).Scan(&conflicts).Error; err != nil {
+		return nil, errors.New("检查时间冲突失败")
+	}
+	if len(conflicts) == 0 {
+		return nil, nil
+	}
+	return &conflicts[0], nil
+}
+
+// checkTimeConflict 检查时间冲突：无冲突返回空串；有冲突且允许忽略时返回写入状态日志的说明；否则返回错误
+func checkTimeConflict(tx *gorm.DB, userID int, activity *model.Activity, statuses []string, override bool) (string, error) {
+	conflict, err := findConflictingActivity(tx, userID, activity, statuses)
+	if err != nil || conflict == nil {
+		return "", err
+	}
+	if override {
+		return fmt.Sprintf("管理员已忽略与活动“%s”的时间冲突", conflict.Title), nil
+	}
+	return "", &CodedError{
+		Code: ErrCodeTimeConflict,
+		Message: fmt.Sprintf("与已报名的活动“%s”（%s）时间冲突",
+			conflict.Title, conflict.ActivityTime.Format("2006-01-02 15:04")),
+	}
+}
+
+// requireConflictOverride 只有管理员才能忽略时间冲突，override为false时不检查
+func requireConflictOverride(override bool, handlerID *int) error {
+	if !override {
+		return nil
+	}
+	if handlerID == nil {
+		return errors.New("忽略时间冲突须由管理员操作")
+	}
+	admin, err := isAdmin(*handlerID)
+	if err != nil {
+		return err
+	}
+	if !admin {
+		return errors.New("忽略时间冲突须由管理员操作")
+	}
+	return nil
+}
+
+// joinComment 合并审核意见和系统说明
+func joinComment(comment, note string) string {
+	if note == "" {
+		return comment
+	}
+	if comment == "" {
+		return note
+	}
+	return comment + "；" + note
+}
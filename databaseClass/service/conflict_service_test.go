@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+// conflictBySQL 按MySQL语义求 activityConflictCondition 的值：
+// TIMEDIFF 得到两个开始时间（精确到秒）的差，HOUR 取小时部分（截断），再取绝对值与2比较
+func conflictBySQL(a1, a2 time.Time) bool {
+	diff := a1.Sub(a2)
+	if diff < 0 {
+		diff = -diff
+	}
+	return int(diff/time.Hour) < 2
+}
+
+func TestActivityConflictCondition(t *testing.T) {
+	// 条件改动时须同步更新下面的边界用例和需求清单中的说明
+	const want = "ABS(HOUR(TIMEDIFF(a1.activity_time, a2.activity_time))) < 2"
+	if activityConflictCondition != want {
+		t.Fatalf("activityConflictCondition = %q, want %q", activityConflictCondition, want)
+	}
+
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	tests := []struct {
+		name   string
+		offset time.Duration
+		want   bool
+	}{
+		{"同一时间", 0, true},
+		{"晚1小时", time.Hour, true},
+		{"晚1小时59分59秒", 2*time.Hour - time.Second, true},
+		{"早1小时59分59秒", -(2*time.Hour - time.Second), true},
+		{"恰好晚2小时", 2 * time.Hour, false},
+		{"恰好早2小时", -2 * time.Hour, false},
+		{"晚2小时1秒", 2*time.Hour + time.Second, false},
+		{"次日同一时间", 24 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := base.Add(tt.offset)
+			if got := conflictBySQL(base, other); got != tt.want {
+				t.Errorf("开始时间 %s 与 %s：冲突 = %v, want %v", base.Format(time.DateTime), other.Format(time.DateTime), got, tt.want)
+			}
+			// 冲突判断对两个活动对称
+			if got := conflictBySQL(other, base); got != tt.want {
+				t.Errorf("交换a1、a2后：冲突 = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
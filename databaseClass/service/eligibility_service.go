@@ -0,0 +1,196 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"volunteer-system/config"
+	"volunteer-system/model"
+
+	"gorm.io/gorm"
+)
+
+// RuleCheckResult 单条资格规则的检查结果
+type RuleCheckResult struct {
+	Rule   model.EligibilityRule `json:"rule"`
+	Passed bool                  `json:"passed"`
+	Reason string                `json:"reason"`
+}
+
+// EligibilityResult 用户对某活动的报名资格说明
+type EligibilityResult struct {
+	ActivityID int               `json:"activity_id"`
+	UserID     int               `json:"user_id"`
+	Eligible   bool              `json:"eligible"`
+	Rules      []RuleCheckResult `json:"rules"`
+}
+
+// EligibilityError 报名资格不满足，携带第一条未通过的规则
+type EligibilityError struct {
+	Result RuleCheckResult
+}
+
+func (e *EligibilityError) Error() string {
+	return "不满足报名条件：" + e.Result.Reason
+}
+
+// volunteerProfile 资格检查所需的用户信息，一次加载后可检查多个活动
+type volunteerProfile struct {
+	DeptID         *int
+	CompletedCount int64
+	Trainings      map[string]bool
+}
+
+func loadVolunteerProfile(userID int) (*volunteerProfile, error) {
+	var user model.User
+	if err := config.DB.First(&user, "user_id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, errors.New("查询用户失败")
+	}
+
+	profile := &volunteerProfile{
+		DeptID:    user.DeptID,
+		Trainings: make(map[string]bool),
+	}
+
+	// 已完成的活动：已签到参加，或报名已批准且活动时间已过
+	if err := config.DB.Table("Application").
+		Joins("JOIN Activity ON Application.activity_id = Activity.activity_id").
+		Where("Application.user_id = ? AND (Application.current_status = ? OR (Application.current_status = ? AND Activity.activity_time < NOW()))",
+			userID, model.AppStatusAttended, model.AppStatusApproved).
+		Count(&profile.CompletedCount).Error; err != nil {
+		return nil, errors.New("查询已完成活动数失败")
+	}
+
+	var trainings []model.UserTraining
+	if err := config.DB.Where("user_id = ?", userID).Find(&trainings).Error; err != nil {
+		return nil, errors.New("查询培训记录失败")
+	}
+	for _, t := range trainings {
+		profile.Trainings[strings.TrimSpace(t.TrainingName)] = true
+	}
+
+	return profile, nil
+}
+
+func (p *volunteerProfile) checkRule(rule model.EligibilityRule) RuleCheckResult {
+	result := RuleCheckResult{Rule: rule}
+
+	switch rule.Type {
+	case model.RuleTypeDept:
+		if p.DeptID != nil {
+			for _, id := range rule.DeptIDs {
+				if id == *p.DeptID {
+					result.Passed = true
+					break
+				}
+			}
+		}
+		if !result.Passed {
+			result.Reason = fmt.Sprintf("仅限部门 %s 的成员报名", joinInts(rule.DeptIDs))
+		}
+	case model.RuleTypeMinCompleted:
+		result.Passed = p.CompletedCount >= int64(rule.MinCompleted)
+		if !result.Passed {
+			result.Reason = fmt.Sprintf("需至少完成 %d 次志愿活动（当前 %d 次）", rule.MinCompleted, p.CompletedCount)
+		}
+	case model.RuleTypeTraining:
+		result.Passed = p.Trainings[strings.TrimSpace(rule.Training)]
+		if !result.Passed {
+			result.Reason = fmt.Sprintf("需完成培训：%s", rule.Training)
+		}
+	default:
+		result.Reason = "未知的资格规则类型：" + rule.Type
+	}
+
+	return result
+}
+
+// check 依次检查所有规则，返回全部结果和第一条未通过的规则
+func (p *volunteerProfile) check(rules model.EligibilityRules) ([]RuleCheckResult, *RuleCheckResult) {
+	results := make([]RuleCheckResult, 0, len(rules))
+	var failed *RuleCheckResult
+	for _, rule := range rules {
+		r := p.checkRule(rule)
+		results = append(results, r)
+		if !r.Passed && failed == nil {
+			failed = &results[len(results)-1]
+		}
+	}
+	return results, failed
+}
+
+// ensureEligible 检查用户是否满足活动的全部资格规则
+func ensureEligible(userID int, activity *model.Activity) error {
+	if len(activity.EligibilityRules) == 0 {
+		return nil
+	}
+
+	profile, err := loadVolunteerProfile(userID)
+	if err != nil {
+		return err
+	}
+
+	if _, failed := profile.check(activity.EligibilityRules); failed != nil {
+		return &EligibilityError{Result: *failed}
+	}
+	return nil
+}
+
+// ExplainEligibility 说明用户能否报名某活动，以及每条规则的检查结果
+func ExplainEligibility(userID, activityID int) (*EligibilityResult, error) {
+	var activity model.Activity
+	if err := config.DB.First(&activity, "activity_id = ?", activityID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("活动不存在")
+		}
+		return nil, errors.New("查询活动失败")
+	}
+
+	profile, err := loadVolunteerProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, failed := profile.check(activity.EligibilityRules)
+	return &EligibilityResult{
+		ActivityID: activityID,
+		UserID:     userID,
+		Eligible:   failed == nil,
+		Rules:      results,
+	}, nil
+}
+
+// validateEligibilityRules 校验创建/修改活动时提交的资格规则
+func validateEligibilityRules(rules model.EligibilityRules) error {
+	for _, rule := range rules {
+		switch rule.Type {
+		case model.RuleTypeDept:
+			if len(rule.DeptIDs) == 0 {
+				return errors.New("部门规则必须指定至少一个部门")
+			}
+		case model.RuleTypeMinCompleted:
+			if rule.MinCompleted <= 0 {
+				return errors.New("完成次数规则的次数必须大于0")
+			}
+		case model.RuleTypeTraining:
+			if strings.TrimSpace(rule.Training) == "" {
+				return errors.New("培训规则必须指定培训名称")
+			}
+		default:
+			return errors.New("未知的资格规则类型：" + rule.Type)
+		}
+	}
+	return nil
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		parts = append(parts, fmt.Sprintf("%d", v))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/mailer"
+	"volunteer-system/model"
+
+	"gorm.io/gorm"
+)
+
+// mailSender 投递邮件的方式，默认只写日志
+var mailSender mailer.Sender = mailer.LogSender{}
+
+// UseMailSender 设置邮件投递方式，须在启动后台任务之前调用
+func UseMailSender(sender mailer.Sender) {
+	mailSender = sender
+}
+
+// emailBatchSize 每次任务最多投递的邮件数
+const emailBatchSize = 100
+
+// enqueueEmail 在tx中按用户的语言渲染模板并写入待发送邮件，用户未设置邮箱时跳过
+func enqueueEmail(tx *gorm.DB, userID int, templateName string, data map[string]interface{}) error {
+	var user model.User
+	if err := tx.Select("user_id", "username", "email", "locale").First(&user, "user_id = ?", userID).Error; err != nil {
+		return errors.New("查询用户失败")
+	}
+	if user.Email == "" {
+		return nil
+	}
+
+	locale := user.Locale
+	if locale == "" {
+		locale = config.Mail.DefaultLocale
+	}
+	data["Username"] = user.Username
+
+	subject, body, err := mailer.Render(locale, templateName, data)
+	if err != nil {
+		return errors.New("生成邮件失败：" + err.Error())
+	}
+
+	now := time.Now()
+	email := model.EmailOutbox{
+		UserID:        &user.UserID,
+		ToAddress:     user.Email,
+		Template:      templateName,
+		Subject:       subject,
+		Body:          body,
+		Status:        model.EmailStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	if err := tx.Create(&email).Error; err != nil {
+		return errors.New("保存待发送邮件失败")
+	}
+	return nil
+}
+
+// credentialTemplates 正文含一次性凭据（如密码重置链接）的邮件模板，
+// 发送成功或最终失败后清除正文，不在待发送邮件表中长期保留
+var credentialTemplates = map[string]bool{
+	mailer.TemplatePasswordReset: true,
+}
+
+// redactedEmailBody 清除后的邮件正文
+const redactedEmailBody = "（正文含一次性凭据，已在投递结束后清除）"
+
+// backoffDelay 第n次失败后的等待时间：从base开始每次翻倍，最长maxDelay
+func backoffDelay(attempts int, base, maxDelay time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+// DeliverEmails 定时任务：投递到期的待发送邮件，失败的按指数退避重试，返回成功发送的邮件数
+func DeliverEmails(ctx context.Context) (int64, error) {
+	var emails []model.EmailOutbox
+	if err := config.DB.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", model.EmailStatusPending, time.Now()).
+		Order("email_id ASC").
+		Limit(emailBatchSize).
+		Find(&emails).Error; err != nil {
+		return 0, errors.New("查询待发送邮件失败")
+	}
+
+	var sent int64
+	for i := range emails {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+		email := &emails[i]
+
+		sendErr := mailSender.Send(ctx, mailer.Message{
+			From:    config.Mail.From,
+			To:      email.ToAddress,
+			Subject: email.Subject,
+			Body:    email.Body,
+		})
+
+		now := time.Now()
+		updates := map[string]interface{}{"attempts": email.Attempts + 1}
+		if sendErr == nil {
+			updates["status"] = model.EmailStatusSent
+			updates["sent_at"] = now
+			updates["last_error"] = ""
+			sent++
+		} else {
+			log.Printf("发送邮件失败 (邮件ID:%d, 第%d次): %v", email.EmailID, email.Attempts+1, sendErr)
+			updates["last_error"] = sendErr.Error()
+			if email.Attempts+1 >= config.Mail.MaxAttempts {
+				updates["status"] = model.EmailStatusFailed
+			} else {
+				updates["next_attempt_at"] = now.Add(backoffDelay(email.Attempts+1, config.Mail.RetryBaseDelay, config.Mail.RetryMaxDelay))
+			}
+		}
+		if _, done := updates["status"]; done && credentialTemplates[email.Template] {
+			updates["body"] = redactedEmailBody
+		}
+
+		if err := config.DB.Model(email).Updates(updates).Error; err != nil {
+			log.Printf("更新邮件状态失败 (邮件ID:%d): %v", email.EmailID, err)
+		}
+	}
+	return sent, nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		base     time.Duration
+		maxDelay time.Duration
+		want     time.Duration
+	}{
+		{"尚未失败", 0, time.Minute, 2 * time.Hour, time.Minute},
+		{"第1次失败", 1, time.Minute, 2 * time.Hour, time.Minute},
+		{"第2次失败翻倍", 2, time.Minute, 2 * time.Hour, 2 * time.Minute},
+		{"第3次失败", 3, time.Minute, 2 * time.Hour, 4 * time.Minute},
+		{"第7次失败", 7, time.Minute, 2 * time.Hour, 64 * time.Minute},
+		{"第8次失败达到上限", 8, time.Minute, 2 * time.Hour, 2 * time.Hour},
+		{"多次失败不超过上限", 100, time.Minute, 2 * time.Hour, 2 * time.Hour},
+		{"恰好等于上限", 3, 30 * time.Minute, 2 * time.Hour, 2 * time.Hour},
+		{"起始值大于上限", 1, 3 * time.Hour, 2 * time.Hour, 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backoffDelay(tt.attempts, tt.base, tt.maxDelay); got != tt.want {
+				t.Errorf("backoffDelay(%d, %v, %v) = %v, want %v", tt.attempts, tt.base, tt.maxDelay, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package service
+
+// CodedError 带错误码的业务错误，便于前端按错误码区分处理
+type CodedError struct {
+	Code    string
+	Message string
+}
+
+func (e *CodedError) Error() string {
+	return e.Message
+}
+
+// 错误码
+const (
+	ErrCodeInvalidStatus     = "INVALID_STATUS"
+	ErrCodeIllegalTransition = "ILLEGAL_TRANSITION"
+	ErrCodeStatusUnchanged   = "STATUS_UNCHANGED"
+	ErrCodeStatusConflict    = "STATUS_CONFLICT"
+	ErrCodeParticipationBan  = "PARTICIPATION_BLOCKED"
+	ErrCodeTimeConflict      = "TIME_CONFLICT"
+	ErrCodeJobNotFound       = "JOB_NOT_FOUND"
+	ErrCodeJobRunning        = "JOB_RUNNING"
+	ErrCodeForbidden         = "FORBIDDEN"
+)
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"volunteer-system/config"
+	"volunteer-system/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	minRating        = 1
+	maxRating        = 5
+	maxFeedbackRunes = 500
+)
+
+// normalizeRating 校验评分（1-5分）和评价内容
+func normalizeRating(rating int, comment string) (string, error) {
+	if rating < minRating || rating > maxRating {
+		return "", errors.New("评分必须在1到5之间")
+	}
+	comment = strings.TrimSpace(comment)
+	if utf8.RuneCountInString(comment) > maxFeedbackRunes {
+		return "", errors.New("评价内容不能超过500字")
+	}
+	return comment, nil
+}
+
+// SubmitActivityFeedback 已参加活动的志愿者为活动评分并填写反馈，重复提交则更新
+func SubmitActivityFeedback(activityID int, req *model.SubmitFeedbackRequest) (*model.ActivityFeedback, error) {
+	comment, err := normalizeRating(req.Rating, req.Comment)
+	if err != nil {
+		return nil, err
+	}
+
+	app, err := findUserApplication(config.DB, req.UserID, activityID)
+	if err != nil {
+		return nil, err
+	}
+	if app.CurrentStatus != model.AppStatusAttended {
+		return nil, errors.New("只有参加了活动的志愿者才能评价")
+	}
+
+	var feedback model.ActivityFeedback
+	err = config.DB.Where("application_id = ?", app.ApplicationID).First(&feedback).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("查询活动反馈失败")
+	}
+
+	now := time.Now()
+	if feedback.FeedbackID == 0 {
+		feedback = model.ActivityFeedback{
+			ApplicationID: app.ApplicationID,
+			ActivityID:    activityID,
+			UserID:        req.UserID,
+			CreatedAt:     now,
+		}
+	}
+	feedback.Rating = req.Rating
+	feedback.Comment = comment
+	feedback.UpdatedAt = now
+
+	if err := config.DB.Save(&feedback).Error; err != nil {
+		return nil, errors.New("保存活动反馈失败")
+	}
+	return &feedback, nil
+}
+
+// ListActivityFeedback 查看活动的评分和反馈
+func ListActivityFeedback(activityID int) ([]model.ActivityFeedbackInfo, error) {
+	var feedback []model.ActivityFeedbackInfo
+	if err := config.DB.Table("ActivityFeedback f").
+		Select("f.*, u.username").
+		Joins("JOIN User u ON f.user_id = u.user_id").
+		Where("f.activity_id = ?", activityID).
+		Order("f.updated_at DESC").
+		Scan(&feedback).Error; err != nil {
+		return nil, errors.New("查询活动反馈失败")
+	}
+	return feedback, nil
+}
+
+// RateVolunteer 管理员评价参加了活动的志愿者，评价不对志愿者公开；重复评价则更新
+func RateVolunteer(appID int, req *model.RateVolunteerRequest) (*model.VolunteerRating, error) {
+	comment, err := normalizeRating(req.Rating, req.Comment)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := isAdmin(req.HandlerID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin {
+		return nil, errors.New("只有管理员可以评价志愿者")
+	}
+
+	var app model.Application
+	if err := config.DB.First(&app, "application_id = ?", appID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("报名记录不存在")
+		}
+		return nil, errors.New("查询报名记录失败")
+	}
+	if app.CurrentStatus != model.AppStatusAttended {
+		return nil, errors.New("只能评价参加了活动的志愿者")
+	}
+
+	var rating model.VolunteerRating
+	err = config.DB.Where("application_id = ?", appID).First(&rating).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("查询志愿者评价失败")
+	}
+
+	now := time.Now()
+	if rating.RatingID == 0 {
+		rating = model.VolunteerRating{
+			ApplicationID: app.ApplicationID,
+			ActivityID:    app.ActivityID,
+			UserID:        app.UserID,
+			CreatedAt:     now,
+		}
+	}
+	rating.RaterID = req.HandlerID
+	rating.Rating = req.Rating
+	rating.Comment = comment
+	rating.UpdatedAt = now
+
+	if err := config.DB.Save(&rating).Error; err != nil {
+		return nil, errors.New("保存志愿者评价失败")
+	}
+	return &rating, nil
+}
+
+// GetUserVolunteerRatings 管理员查看志愿者收到的评价及平均分
+func GetUserVolunteerRatings(userID, handlerID int) (*model.UserVolunteerRatings, error) {
+	admin, err := isAdmin(handlerID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin {
+		return nil, errors.New("只有管理员可以查看志愿者评价")
+	}
+
+	result := &model.UserVolunteerRatings{UserID: userID}
+	if err := config.DB.Table("VolunteerRating r").
+		Select("r.*, a.title AS activity_title, u.username AS rater_name").
+		Joins("JOIN Activity a ON r.activity_id = a.activity_id").
+		Joins("JOIN User u ON r.rater_id = u.user_id").
+		Where("r.user_id = ?", userID).
+		Order("r.updated_at DESC").
+		Scan(&result.Ratings).Error; err != nil {
+		return nil, errors.New("查询志愿者评价失败")
+	}
+
+	total := 0
+	for _, r := range result.Ratings {
+		total += r.Rating
+	}
+	result.RatingCount = len(result.Ratings)
+	if result.RatingCount > 0 {
+		result.AvgRating = math.Round(float64(total)/float64(result.RatingCount)*100) / 100
+	}
+	return result, nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"volunteer-system/model"
+)
+
+// validateFormSchema 校验创建/修改活动时提交的报名表单定义
+func validateFormSchema(schema model.FormSchema) error {
+	keys := make(map[string]bool, len(schema))
+	for _, field := range schema {
+		key := strings.TrimSpace(field.Key)
+		if key == "" {
+			return errors.New("表单字段的key不能为空")
+		}
+		if keys[key] {
+			return fmt.Errorf("表单字段 %s 重复", key)
+		}
+		keys[key] = true
+
+		if strings.TrimSpace(field.Label) == "" {
+			return fmt.Errorf("表单字段 %s 缺少标题", key)
+		}
+
+		switch field.Type {
+		case model.FormFieldText, model.FormFieldNumber, model.FormFieldDate:
+		case model.FormFieldSingleChoice, model.FormFieldMultiChoice:
+			if len(field.Options) == 0 {
+				return fmt.Errorf("选择题 %s 必须设置选项", field.Label)
+			}
+		default:
+			return fmt.Errorf("表单字段 %s 的类型不支持：%s", field.Label, field.Type)
+		}
+	}
+	return nil
+}
+
+// validateFormAnswers 按活动的表单定义校验报名回答，返回规范化后的回答
+func validateFormAnswers(schema model.FormSchema, answers model.FormAnswers) (model.FormAnswers, error) {
+	fields := make(map[string]bool, len(schema))
+	for _, field := range schema {
+		fields[field.Key] = true
+	}
+	for key := range answers {
+		if !fields[key] {
+			return nil, fmt.Errorf("表单中没有问题 %s", key)
+		}
+	}
+
+	normalized := make(model.FormAnswers, len(schema))
+	for _, field := range schema {
+		raw, ok := answers[field.Key]
+		if !ok || raw == nil || raw == "" {
+			if field.Required {
+				return nil, fmt.Errorf("请填写：%s", field.Label)
+			}
+			continue
+		}
+
+		value, err := normalizeAnswer(field, raw)
+		if err != nil {
+			return nil, err
+		}
+		if field.Required && isEmptyAnswer(value) {
+			return nil, fmt.Errorf("请填写：%s", field.Label)
+		}
+		if !isEmptyAnswer(value) {
+			normalized[field.Key] = value
+		}
+	}
+
+	return normalized, nil
+}
+
+func normalizeAnswer(field model.FormField, raw interface{}) (interface{}, error) {
+	switch field.Type {
+	case model.FormFieldText:
+		text, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s 必须是文本", field.Label)
+		}
+		return strings.TrimSpace(text), nil
+
+	case model.FormFieldNumber:
+		switch v := raw.(type) {
+		case float64:
+			return v, nil
+		case string:
+			n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s 必须是数字", field.Label)
+			}
+			return n, nil
+		}
+		return nil, fmt.Errorf("%s 必须是数字", field.Label)
+
+	case model.FormFieldDate:
+		text, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s 必须是日期", field.Label)
+		}
+		d, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(text), time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("%s 的日期格式应为 YYYY-MM-DD", field.Label)
+		}
+		return d.Format("2006-01-02"), nil
+
+	case model.FormFieldSingleChoice:
+		choice, ok := raw.(string)
+		if !ok || !containsOption(field.Options, choice) {
+			return nil, fmt.Errorf("%s 的选项无效", field.Label)
+		}
+		return choice, nil
+
+	case model.FormFieldMultiChoice:
+		items, ok := raw.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s 必须是选项列表", field.Label)
+		}
+		choices := make([]string, 0, len(items))
+		for _, item := range items {
+			choice, ok := item.(string)
+			if !ok || !containsOption(field.Options, choice) {
+				return nil, fmt.Errorf("%s 的选项无效", field.Label)
+			}
+			choices = append(choices, choice)
+		}
+		return choices, nil
+	}
+
+	return nil, fmt.Errorf("表单字段 %s 的类型不支持：%s", field.Label, field.Type)
+}
+
+func containsOption(options []string, choice string) bool {
+	for _, option := range options {
+		if option == choice {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyAnswer(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return v == ""
+	case []string:
+		return len(v) == 0
+	}
+	return value == nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/model"
+
+	"gorm.io/gorm"
+)
+
+// computedHoursSources 由考勤自动计算的台账来源，每条报名最多一条
+var computedHoursSources = []string{model.HoursSourceAttendance, model.HoursSourceDuration}
+
+func roundHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
+
+// recordAttendanceHours 按考勤结果写入（或更新）报名的服务时长：
+// 有签到签退时间按实际时长计算，否则按活动时长计算
+func recordAttendanceHours(tx *gorm.DB, app *model.Application, attendance *model.Attendance) error {
+	var activity model.Activity
+	if err := tx.First(&activity, "activity_id = ?", app.ActivityID).Error; err != nil {
+		return errors.New("查询活动信息失败")
+	}
+
+	source := model.HoursSourceDuration
+	hours := float64(activity.DurationMinutes) / 60
+	if attendance != nil && attendance.CheckInTime != nil && attendance.CheckOutTime != nil {
+		source = model.HoursSourceAttendance
+		hours = attendance.CheckOutTime.Sub(*attendance.CheckInTime).Hours()
+	}
+
+	var entry model.ServiceHoursEntry
+	err := tx.Where("application_id = ? AND source IN ?", app.ApplicationID, computedHoursSources).First(&entry).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("查询服务时长记录失败")
+	}
+
+	entry.UserID = app.UserID
+	entry.ApplicationID = &app.ApplicationID
+	entry.ActivityID = &app.ActivityID
+	entry.Hours = roundHours(hours)
+	entry.Source = source
+	entry.CreatedAt = time.Now()
+	if err := tx.Save(&entry).Error; err != nil {
+		return errors.New("保存服务时长失败")
+	}
+	return nil
+}
+
+// FinalizeAttendanceHours 定时任务：活动结束后，已签到但未签退的报名按活动时长记录服务时长，返回记录的报名数
+func FinalizeAttendanceHours(ctx context.Context) (int64, error) {
+	var apps []model.Application
+	if err := config.DB.WithContext(ctx).Table("Application app").
+		Select("app.*").
+		Joins("JOIN Activity a ON app.activity_id = a.activity_id").
+		Where("app.current_status = ? AND app.attendance_status = ?", model.AppStatusAttended, model.AttendanceCheckedIn).
+		Where("DATE_ADD(a.activity_time, INTERVAL a.duration_minutes MINUTE) < NOW()").
+		Where("NOT EXISTS (SELECT 1 FROM ServiceHoursEntry h WHERE h.application_id = app.application_id AND h.source IN ?)", computedHoursSources).
+		Find(&apps).Error; err != nil {
+		return 0, errors.New("查询未签退报名失败")
+	}
+
+	var recorded int64
+	for i := range apps {
+		if ctx.Err() != nil {
+			return recorded, ctx.Err()
+		}
+		app := &apps[i]
+		err := transaction(func(tx *gorm.DB) error {
+			return recordAttendanceHours(tx, app, nil)
+		})
+		if err != nil {
+			log.Printf("记录服务时长失败 (报名ID:%d): %v", app.ApplicationID, err)
+			continue
+		}
+		recorded++
+	}
+	return recorded, nil
+}
+
+// removeAttendanceHours 登记缺席时撤销该报名自动计算的服务时长
+func removeAttendanceHours(tx *gorm.DB, appID int) error {
+	if err := tx.Where("application_id = ? AND source IN ?", appID, computedHoursSources).
+		Delete(&model.ServiceHoursEntry{}).Error; err != nil {
+		return errors.New("撤销服务时长失败")
+	}
+	return nil
+}
+
+// AdjustServiceHours 管理员调整用户服务时长（追加一条调整记录）
+func AdjustServiceHours(userID int, req *model.AdjustServiceHoursRequest) (*model.ServiceHoursEntry, error) {
+	if err := requireAdmin(req.HandlerID, "调整服务时长"); err != nil {
+		return nil, err
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, errors.New("调整服务时长必须填写原因")
+	}
+	if req.Hours == 0 {
+		return nil, errors.New("调整的时长不能为0")
+	}
+
+	var user model.User
+	if err := config.DB.First(&user, "user_id = ?", userID).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	entry := model.ServiceHoursEntry{
+		UserID:    userID,
+		Hours:     roundHours(req.Hours),
+		Source:    model.HoursSourceAdjustment,
+		Reason:    reason,
+		CreatedBy: &req.HandlerID,
+		CreatedAt: time.Now(),
+	}
+
+	if req.ApplicationID != nil {
+		var app model.Application
+		if err := config.DB.First(&app, "application_id = ?", *req.ApplicationID).Error; err != nil {
+			return nil, errors.New("报名记录不存在")
+		}
+		if app.UserID != userID {
+			return nil, errors.New("该报名不属于此用户")
+		}
+		entry.ApplicationID = &app.ApplicationID
+		entry.ActivityID = &app.ActivityID
+	}
+
+	if err := config.DB.Create(&entry).Error; err != nil {
+		return nil, errors.New("保存服务时长调整失败")
+	}
+	return &entry, nil
+}
+
+// GetUserServiceHours 查询用户服务时长合计及明细
+func GetUserServiceHours(userID int) (*model.UserServiceHours, error) {
+	var user model.User
+	if err := config.DB.First(&user, "user_id = ?", userID).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	result := &model.UserServiceHours{UserID: userID}
+	if err := config.DB.Table("ServiceHoursEntry h").
+		Select(`h.entry_id, h.application_id, h.activity_id, COALESCE(a.title, '') AS activity_title,
+			h.hours, h.source, COALESCE(h.reason, '') AS reason, h.created_by, h.created_at`).
+		Joins("LEFT JOIN Activity a ON h.activity_id = a.activity_id").
+		Where("h.user_id = ?", userID).
+		Order("h.created_at DESC, h.entry_id DESC").
+		Scan(&result.Entries).Error; err != nil {
+		return nil, errors.New("查询服务时长失败")
+	}
+
+	for _, entry := range result.Entries {
+		result.TotalHours += entry.Hours
+	}
+	result.TotalHours = roundHours(result.TotalHours)
+	return result, nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/model"
+	"volunteer-system/scheduler"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// jobScheduler 注册了后台任务的调度器，供管理接口查询和手动触发
+var jobScheduler *scheduler.Scheduler
+
+// maxJobErrorLength 运行记录中错误信息的最大长度（字符数）
+const maxJobErrorLength = 1000
+
+// jobStore 将任务运行记录和暂停状态保存在 JobRun / JobState 表中
+type jobStore struct{}
+
+func (jobStore) IsPaused(ctx context.Context, name string) (bool, error) {
+	var state model.JobState
+	err := config.DB.WithContext(ctx).First(&state, "job_name = ?", name).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return state.Paused, nil
+}
+
+func (jobStore) StartRun(ctx context.Context, name, trigger string, triggeredBy *int) (int64, error) {
+	run := model.JobRun{
+		JobName:     name,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		Status:      model.JobRunStatusRunning,
+		StartedAt:   time.Now(),
+	}
+	if err := config.DB.WithContext(ctx).Create(&run).Error; err != nil {
+		return 0, err
+	}
+	return run.RunID, nil
+}
+
+func (jobStore) FinishRun(ctx context.Context, runID int64, affected int64, runErr error) error {
+	updates := map[string]interface{}{
+		"status":        model.JobRunStatusSucceeded,
+		"finished_at":   time.Now(),
+		"affected_rows": affected,
+	}
+	if runErr != nil {
+		msg := []rune(runErr.Error())
+		if len(msg) > maxJobErrorLength {
+			msg = msg[:maxJobErrorLength]
+		}
+		updates["status"] = model.JobRunStatusFailed
+		updates["error_message"] = string(msg)
+	}
+	return config.DB.WithContext(ctx).Model(&model.JobRun{}).
+		Where("run_id = ?", runID).
+		Updates(updates).Error
+}
+
+func findJob(name string) (*scheduler.Job, error) {
+	if jobScheduler != nil {
+		for _, job := range jobScheduler.Jobs() {
+			if job.Name == name {
+				return &job, nil
+			}
+		}
+	}
+	return nil, &CodedError{Code: ErrCodeJobNotFound, Message: "任务不存在：" + name}
+}
+
+// ListJobs 列出所有后台任务及其暂停状态、最近一次运行和最近一次成功时间
+func ListJobs(handlerID int) ([]model.JobInfo, error) {
+	if err := requireAdmin(handlerID, "查看后台任务"); err != nil {
+		return nil, err
+	}
+	if jobScheduler == nil {
+		return []model.JobInfo{}, nil
+	}
+
+	var states []model.JobState
+	if err := config.DB.Find(&states).Error; err != nil {
+		return nil, errors.New("查询任务状态失败")
+	}
+	paused := make(map[string]bool, len(states))
+	for _, state := range states {
+		paused[state.JobName] = state.Paused
+	}
+
+	jobs := jobScheduler.Jobs()
+	infos := make([]model.JobInfo, 0, len(jobs))
+	for _, job := range jobs {
+		info := model.JobInfo{
+			Name:            job.Name,
+			IntervalSeconds: int64(job.Interval / time.Second),
+			Paused:          paused[job.Name],
+		}
+
+		var last model.JobRun
+		err := config.DB.Where("job_name = ?", job.Name).Order("run_id DESC").Take(&last).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("查询任务运行记录失败")
+		}
+		if err == nil {
+			info.LastRun = &last
+		}
+
+		var lastSuccess model.JobRun
+		err = config.DB.Where("job_name = ? AND status = ?", job.Name, model.JobRunStatusSucceeded).
+			Order("run_id DESC").Take(&lastSuccess).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("查询任务运行记录失败")
+		}
+		if err == nil {
+			info.LastSucceededAt = lastSuccess.FinishedAt
+		}
+
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// ListJobRuns 查看任务的运行历史（按开始时间从新到旧）
+func ListJobRuns(name string, handlerID, limit int) ([]model.JobRun, error) {
+	if err := requireAdmin(handlerID, "查看后台任务"); err != nil {
+		return nil, err
+	}
+	if _, err := findJob(name); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	var runs []model.JobRun
+	if err := config.DB.Where("job_name = ?", name).
+		Order("run_id DESC").
+		Limit(limit).
+		Find(&runs).Error; err != nil {
+		return nil, errors.New("查询任务运行记录失败")
+	}
+	return runs, nil
+}
+
+// TriggerJob 管理员立即执行一次任务，任务正在执行（本实例或其他实例）时返回 JOB_RUNNING
+func TriggerJob(ctx context.Context, name string, handlerID int) (*model.JobRun, error) {
+	if err := requireAdmin(handlerID, "执行后台任务"); err != nil {
+		return nil, err
+	}
+	if _, err := findJob(name); err != nil {
+		return nil, err
+	}
+
+	// 与请求脱离：客户端断开不会中断任务，执行时间由任务自身的超时限制
+	runID, err := jobScheduler.RunNow(context.WithoutCancel(ctx), name, &handlerID)
+	if errors.Is(err, scheduler.ErrJobLocked) {
+		return nil, &CodedError{Code: ErrCodeJobRunning, Message: "任务正在执行，请稍后再试"}
+	}
+	if err != nil {
+		return nil, errors.New("执行任务失败：" + err.Error())
+	}
+
+	var run model.JobRun
+	if err := config.DB.First(&run, "run_id = ?", runID).Error; err != nil {
+		return nil, errors.New("查询任务运行记录失败")
+	}
+	return &run, nil
+}
+
+// SetJobPaused 暂停或恢复任务的定时执行，对所有实例生效
+func SetJobPaused(name string, handlerID int, paused bool) error {
+	if err := requireAdmin(handlerID, "暂停或恢复后台任务"); err != nil {
+		return err
+	}
+	if _, err := findJob(name); err != nil {
+		return err
+	}
+
+	state := model.JobState{
+		JobName:   name,
+		Paused:    paused,
+		UpdatedBy: &handlerID,
+		UpdatedAt: time.Now(),
+	}
+	if err := config.DB.Clauses(clause.OnConflict{
+		UpdateAll: true,
+	}).Create(&state).Error; err != nil {
+		return errors.New("更新任务状态失败")
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"volunteer-system/config"
+	"volunteer-system/scheduler"
+)
+
+// 后台定时任务名称
+const (
+	JobPublishActivities = "publish_activities"
+	JobLotteryDraws      = "lottery_draws"
+	JobSyncRegistration  = "sync_registration"
+	JobExpireActivities  = "expire_activities"
+	JobMarkNoShows       = "mark_no_shows"
+	JobFinalizeHours     = "finalize_hours"
+	JobSendReminders     = "send_reminders"
+	JobDeliverEmails     = "deliver_emails"
+	JobDeliverWebhooks   = "deliver_webhooks"
+	JobPruneEvents       = "prune_realtime_events"
+)
+
+// RegisterJobs 向调度器注册所有后台任务，执行间隔和超时见 config.JobInterval、config.JobTimeout；
+// 运行记录和暂停状态保存在数据库中，管理接口通过同一调度器查询和手动触发任务
+func RegisterJobs(s *scheduler.Scheduler) error {
+	s.UseStore(jobStore{})
+	jobScheduler = s
+
+	jobs := []struct {
+		name string
+		run  scheduler.JobFunc
+	}{
+		// 先发布到期的活动；报名截止的抽签活动先抽签，再关闭报名、处理过期
+		{JobPublishActivities, PublishScheduledActivities},
+		{JobLotteryDraws, RunDueLotteryDraws},
+		{JobSyncRegistration, SyncRegistrationStatus},
+		{JobExpireActivities, ExpireActivities},
+		// 使用签到的活动结束后仍未签到的报名记为未到场
+		{JobMarkNoShows, MarkNoShows},
+		// 活动结束后已签到未签退的报名按活动时长记录服务时长
+		{JobFinalizeHours, FinalizeAttendanceHours},
+		// 活动开始前提醒志愿者、向组织者发送名单汇总
+		{JobSendReminders, SendActivityReminders},
+		// 投递待发送邮件和Webhook，失败的按退避时间重试
+		{JobDeliverEmails, DeliverEmails},
+		{JobDeliverWebhooks, DeliverWebhooks},
+		// 清理各实例都已推送过的实时事件
+		{JobPruneEvents, PruneRealtimeEvents},
+	}
+
+	for _, job := range jobs {
+		if err := s.Register(job.name, config.JobInterval(job.name), config.JobTimeout(job.name), job.run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
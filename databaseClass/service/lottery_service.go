@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	mathrand "math/rand"
+	"sort"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// newLotterySeed 生成抽签用的随机种子
+func newLotterySeed() (int64, error) {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(buf[:]) >> 1), nil
+}
+
+// drawOrder 按种子打乱报名ID得到抽签顺位。
+// 输入先按报名ID排序，保证同一种子、同一批报名总能得到相同的结果。
+func drawOrder(applicationIDs []int, seed int64) []int {
+	order := append([]int(nil), applicationIDs...)
+	sort.Ints(order)
+	rng := mathrand.New(mathrand.NewSource(seed))
+	rng.Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+	return order
+}
+
+// RunLotteryDraw 对报名已截止的抽签活动进行抽签：按顺位批准至满员，其余转为候补。
+// handlerID为nil表示由定时任务发起，否则须为管理员。
+func RunLotteryDraw(activityID int, handlerID *int) (*model.LotteryDrawResult, error) {
+	if handlerID != nil {
+		if err := requireAdmin(*handlerID, "发起抽签"); err != nil {
+			return nil, err
+		}
+	}
+
+	var draw model.LotteryDraw
+	var apps []model.Application
+	var activity model.Activity
+
+	err := transaction(func(tx *gorm.DB) error {
+		// 锁定活动行，防止重复抽签以及与人工批准并发
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&activity, "activity_id = ?", activityID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("活动不存在")
+			}
+			return errors.New("查询活动信息失败")
+		}
+		if activity.ApprovalMode != model.ApprovalModeLottery {
+			return errors.New("该活动不是抽签模式")
+		}
+		if activity.RegistrationDeadline == nil || activity.RegistrationDeadline.After(time.Now()) {
+			return errors.New("报名尚未截止，不能抽签")
+		}
+
+		var drawn int64
+		if err := tx.Model(&model.LotteryDraw{}).Where("activity_id = ?", activityID).Count(&drawn).Error; err != nil {
+			return errors.New("查询抽签记录失败")
+		}
+		if drawn > 0 {
+			return errors.New("该活动已抽签")
+		}
+
+		if err := tx.Where("activity_id = ? AND current_status = ?", activityID, model.AppStatusPending).
+			Find(&apps).Error; err != nil {
+			return errors.New("查询报名记录失败")
+		}
+
+		slotHolders, err := countSlotHolders(tx, activityID)
+		if err != nil {
+			return err
+		}
+		slots := activity.MaxPeople - int(slotHolders)
+
+		seed, err := newLotterySeed()
+		if err != nil {
+			return errors.New("生成抽签种子失败")
+		}
+
+		byID := make(map[int]*model.Application, len(apps))
+		ids := make([]int, 0, len(apps))
+		for i := range apps {
+			byID[apps[i].ApplicationID] = &apps[i]
+			ids = append(ids, apps[i].ApplicationID)
+		}
+
+		draw = model.LotteryDraw{
+			ActivityID:     activityID,
+			Seed:           seed,
+			ApplicantCount: len(apps),
+			DrawnBy:        handlerID,
+			DrawnAt:        time.Now(),
+		}
+		if err := tx.Create(&draw).Error; err != nil {
+			return errors.New("保存抽签记录失败")
+		}
+
+		for i, id := range drawOrder(ids, seed) {
+			app := byID[id]
+			position := i + 1
+
+			result := model.AppStatusWaitlisted
+			comment := fmt.Sprintf("抽签未中，候补第 %d 位", position-max(slots, 0))
+			if position <= slots {
+				result = model.AppStatusApproved
+				comment = fmt.Sprintf("抽签中签（第 %d 位）", position)
+				draw.ApprovedCount++
+			} else {
+				draw.WaitlistedCount++
+			}
+
+			if err := changeApplicationStatus(tx, app, result, handlerID, comment); err != nil {
+				return err
+			}
+			entry := model.LotteryDrawEntry{
+				DrawID:        draw.DrawID,
+				ApplicationID: app.ApplicationID,
+				UserID:        app.UserID,
+				Position:      position,
+				Result:        result,
+			}
+			if err := tx.Create(&entry).Error; err != nil {
+				return errors.New("保存抽签结果失败")
+			}
+		}
+
+		if err := tx.Model(&draw).Updates(map[string]interface{}{
+			"approved_count":   draw.ApprovedCount,
+			"waitlisted_count": draw.WaitlistedCount,
+		}).Error; err != nil {
+			return errors.New("保存抽签记录失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 中签和候补结果随状态变更通知报名者
+	return GetLotteryDraw(activityID)
+}
+
+// RunDueLotteryDraws 定时任务：对报名已截止但尚未抽签的抽签活动进行抽签，返回抽签的活动数
+func RunDueLotteryDraws(ctx context.Context) (int64, error) {
+	var activityIDs []int
+	if err := config.DB.WithContext(ctx).Model(&model.Activity{}).
+		Where("status IN ? AND approval_mode = ? AND registration_deadline <= ?", publishedActivityStatuses, model.ApprovalModeLottery, time.Now()).
+		Where("activity_id NOT IN (SELECT activity_id FROM LotteryDraw)").
+		Pluck("activity_id", &activityIDs).Error; err != nil {
+		return 0, errors.New("查询待抽签活动失败")
+	}
+
+	var drawn int64
+	for _, id := range activityIDs {
+		if ctx.Err() != nil {
+			return drawn, ctx.Err()
+		}
+		if _, err := RunLotteryDraw(id, nil); err != nil {
+			log.Printf("抽签失败 (活动ID:%d): %v", id, err)
+			continue
+		}
+		drawn++
+	}
+	return drawn, nil
+}
+
+// GetLotteryDraw 查询活动的抽签记录和结果，并按保存的种子复核抽签顺位
+func GetLotteryDraw(activityID int) (*model.LotteryDrawResult, error) {
+	var result model.LotteryDrawResult
+	if err := config.DB.Where("activity_id = ?", activityID).First(&result.LotteryDraw).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("该活动尚未抽签")
+		}
+		return nil, errors.New("查询抽签记录失败")
+	}
+
+	if err := config.DB.Table("LotteryDrawEntry e").
+		Select("e.*, u.username").
+		Joins("JOIN User u ON e.user_id = u.user_id").
+		Where("e.draw_id = ?", result.DrawID).
+		Order("e.position ASC").
+		Scan(&result.Entries).Error; err != nil {
+		return nil, errors.New("查询抽签结果失败")
+	}
+
+	ids := make([]int, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		ids = append(ids, entry.ApplicationID)
+	}
+	result.Verified = true
+	for i, id := range drawOrder(ids, result.Seed) {
+		if result.Entries[i].ApplicationID != id {
+			result.Verified = false
+			break
+		}
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestDrawOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []int
+		seed int64
+	}{
+		{"空列表", nil, 1},
+		{"单个报名", []int{7}, 1},
+		{"已排序", []int{1, 2, 3, 4, 5, 6, 7, 8}, 42},
+		{"乱序输入", []int{8, 3, 5, 1, 7, 2, 6, 4}, 42},
+		{"大种子", []int{10, 20, 30, 40, 50}, 1<<62 - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := slices.Clone(tt.ids)
+			got := drawOrder(tt.ids, tt.seed)
+
+			if !slices.Equal(tt.ids, input) {
+				t.Errorf("drawOrder 修改了输入：%v，原为 %v", tt.ids, input)
+			}
+			sorted := slices.Clone(got)
+			sort.Ints(sorted)
+			want := slices.Clone(input)
+			sort.Ints(want)
+			if !slices.Equal(sorted, want) {
+				t.Errorf("drawOrder(%v) = %v，不是输入的一个排列", input, got)
+			}
+			if again := drawOrder(input, tt.seed); !slices.Equal(again, got) {
+				t.Errorf("同一种子两次抽签结果不同：%v 和 %v", got, again)
+			}
+		})
+	}
+}
+
+// 结果只取决于种子和报名集合，与输入顺序无关
+func TestDrawOrderIgnoresInputOrder(t *testing.T) {
+	a := drawOrder([]int{1, 2, 3, 4, 5, 6, 7, 8}, 2024)
+	b := drawOrder([]int{8, 3, 5, 1, 7, 2, 6, 4}, 2024)
+	if !slices.Equal(a, b) {
+		t.Errorf("输入顺序不同导致抽签结果不同：%v 和 %v", a, b)
+	}
+}
+
+func TestDrawOrderDependsOnSeed(t *testing.T) {
+	ids := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	first := drawOrder(ids, 1)
+	for seed := int64(2); seed < 10; seed++ {
+		if !slices.Equal(drawOrder(ids, seed), first) {
+			return
+		}
+	}
+	t.Errorf("不同种子得到的抽签顺位全部相同：%v", first)
+}
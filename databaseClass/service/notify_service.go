@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/model"
+	"volunteer-system/realtime"
+
+	"gorm.io/gorm"
+)
+
+// notifyUser 在tx中为用户创建站内通知，随业务数据一起提交；
+// activityID、applicationID 为通知关联的活动和报名，可为空
+func notifyUser(tx *gorm.DB, userID int, title, content string, activityID, applicationID *int) error {
+	notification := model.Notification{
+		UserID:        userID,
+		Title:         title,
+		Content:       content,
+		ActivityID:    activityID,
+		ApplicationID: applicationID,
+		CreatedAt:     time.Now(),
+	}
+	if err := tx.Create(&notification).Error; err != nil {
+		return errors.New("保存通知失败")
+	}
+
+	publishEvent(tx, realtime.Event{
+		Type:   realtime.EventNotificationCreated,
+		UserID: userID,
+		Data:   notification,
+	})
+	return nil
+}
+
+// notifyApplicants 通知活动中报名仍有效（待审核、候补、已批准）的用户
+func notifyApplicants(tx *gorm.DB, activityID int, title, content string, linkActivity bool) error {
+	var apps []model.Application
+	if err := tx.Where("activity_id = ? AND current_status IN ?", activityID,
+		[]string{model.AppStatusPending, model.AppStatusWaitlisted, model.AppStatusApproved}).
+		Find(&apps).Error; err != nil {
+		return errors.New("查询活动报名失败")
+	}
+
+	for i := range apps {
+		var activityRef, applicationRef *int
+		if linkActivity {
+			activityRef, applicationRef = &apps[i].ActivityID, &apps[i].ApplicationID
+		}
+		if err := notifyUser(tx, apps[i].UserID, title, content, activityRef, applicationRef); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListNotifications 查看用户的通知（从新到旧），unreadOnly为true时只看未读
+func ListNotifications(userID int, unreadOnly bool, limit int) ([]model.Notification, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	query := config.DB.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+
+	var notifications []model.Notification
+	if err := query.Order("notification_id DESC").Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, errors.New("查询通知失败")
+	}
+	return notifications, nil
+}
+
+// CountUnreadNotifications 用户未读通知数
+func CountUnreadNotifications(userID int) (int64, error) {
+	var count int64
+	if err := config.DB.Model(&model.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Count(&count).Error; err != nil {
+		return 0, errors.New("查询未读通知数失败")
+	}
+	return count, nil
+}
+
+// MarkNotificationRead 将用户的一条通知标为已读
+func MarkNotificationRead(userID, notificationID int) error {
+	var notification model.Notification
+	if err := config.DB.First(&notification, "notification_id = ? AND user_id = ?", notificationID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("通知不存在")
+		}
+		return errors.New("查询通知失败")
+	}
+	if notification.IsRead {
+		return nil
+	}
+
+	if err := config.DB.Model(&notification).Updates(map[string]interface{}{
+		"is_read": true,
+		"read_at": time.Now(),
+	}).Error; err != nil {
+		return errors.New("更新通知失败")
+	}
+	return nil
+}
+
+// MarkAllNotificationsRead 将用户所有未读通知标为已读，返回更新的条数
+func MarkAllNotificationsRead(userID int) (int64, error) {
+	result := config.DB.Model(&model.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Updates(map[string]interface{}{
+			"is_read": true,
+			"read_at": time.Now(),
+		})
+	if result.Error != nil {
+		return 0, errors.New("更新通知失败")
+	}
+	return result.RowsAffected, nil
+}
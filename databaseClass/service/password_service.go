@@ -0,0 +1,99 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/mailer"
+	"volunteer-system/model"
+	"volunteer-system/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RequestPasswordReset 生成密码重置令牌并发送重置邮件。
+// 用户不存在或未设置邮箱时同样返回成功，不暴露账户是否存在
+func RequestPasswordReset(username string) error {
+	var user model.User
+	if err := config.DB.Where("username = ?", username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return errors.New("查询用户失败")
+	}
+	if user.Email == "" {
+		log.Printf("用户 %d 未设置邮箱，无法发送密码重置邮件", user.UserID)
+		return nil
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return errors.New("生成重置令牌失败")
+	}
+	token := hex.EncodeToString(raw)
+	now := time.Now()
+
+	return transaction(func(tx *gorm.DB) error {
+		// 之前未使用的令牌作废；待发送邮件中的明文链接随之失效，投递结束后正文被清除
+		if err := tx.Model(&model.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.UserID).
+			Update("used_at", now).Error; err != nil {
+			return errors.New("作废旧的重置令牌失败")
+		}
+
+		resetToken := model.PasswordResetToken{
+			UserID:    user.UserID,
+			TokenHash: hashResetToken(token),
+			ExpiresAt: now.Add(config.Mail.PasswordResetTTL),
+			CreatedAt: now,
+		}
+		if err := tx.Create(&resetToken).Error; err != nil {
+			return errors.New("保存重置令牌失败")
+		}
+
+		return enqueueEmail(tx, user.UserID, mailer.TemplatePasswordReset, map[string]interface{}{
+			"ResetURL":       config.Mail.BaseURL + "/login?reset_token=" + token,
+			"ExpiresMinutes": int(config.Mail.PasswordResetTTL / time.Minute),
+		})
+	})
+}
+
+// ResetPassword 使用重置令牌设置新密码，令牌只能使用一次
+func ResetPassword(token, newPassword string) error {
+	if newPassword == "" {
+		return errors.New("新密码不能为空")
+	}
+
+	return transaction(func(tx *gorm.DB) error {
+		var resetToken model.PasswordResetToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashResetToken(token), time.Now()).
+			First(&resetToken).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("重置链接无效或已过期")
+			}
+			return errors.New("查询重置令牌失败")
+		}
+
+		if err := tx.Model(&model.User{}).
+			Where("user_id = ?", resetToken.UserID).
+			Update("password", utils.MD5Hash(newPassword)).Error; err != nil {
+			return errors.New("更新密码失败")
+		}
+		if err := tx.Model(&resetToken).Update("used_at", time.Now()).Error; err != nil {
+			return errors.New("更新重置令牌失败")
+		}
+		return nil
+	})
+}
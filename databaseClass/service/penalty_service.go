@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/model"
+
+	"gorm.io/gorm"
+)
+
+// findActivePenalty 查询用户当前生效的报名限制，没有则返回nil
+func findActivePenalty(db *gorm.DB, userID int) (*model.ParticipationPenalty, error) {
+	var penalty model.ParticipationPenalty
+	err := db.Where("user_id = ? AND lifted_at IS NULL AND end_time > ?", userID, time.Now()).
+		Order("end_time DESC").
+		First(&penalty).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("查询报名限制失败")
+	}
+	return &penalty, nil
+}
+
+// ensureNotPenalized 报名前检查用户是否因多次未到场被限制
+func ensureNotPenalized(userID int) error {
+	penalty, err := findActivePenalty(config.DB, userID)
+	if err != nil {
+		return err
+	}
+	if penalty != nil {
+		return &CodedError{
+			Code: ErrCodeParticipationBan,
+			Message: fmt.Sprintf("您近期有 %d 次报名后未到场，%s 前不能报名新活动",
+				penalty.NoShowCount, penalty.EndTime.Format("2006-01-02 15:04")),
+		}
+	}
+	return nil
+}
+
+// evaluateNoShowPenalty 记录未到场后检查是否达到限制条件，达到则生成报名限制。
+// 上一次限制开始前的未到场已经处罚过，不再重复计入。
+func evaluateNoShowPenalty(tx *gorm.DB, userID int) error {
+	policy := config.Policy
+	if policy.NoShowThreshold <= 0 {
+		return nil
+	}
+
+	active, err := findActivePenalty(tx, userID)
+	if err != nil || active != nil {
+		return err
+	}
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -policy.NoShowWindowDays)
+
+	var last model.ParticipationPenalty
+	err = tx.Where("user_id = ?", userID).Order("start_time DESC").First(&last).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("查询报名限制失败")
+	}
+	if err == nil && last.StartTime.After(since) {
+		since = last.StartTime
+	}
+
+	var count int64
+	if err := tx.Table("Application app").
+		Joins("JOIN Activity a ON app.activity_id = a.activity_id").
+		Where("app.user_id = ? AND app.current_status = ? AND a.activity_time >= ?", userID, model.AppStatusNoShow, since).
+		Count(&count).Error; err != nil {
+		return errors.New("统计未到场次数失败")
+	}
+	if count < int64(policy.NoShowThreshold) {
+		return nil
+	}
+
+	penalty := model.ParticipationPenalty{
+		UserID:      userID,
+		NoShowCount: int(count),
+		Reason:      fmt.Sprintf("%d 天内未到场 %d 次", policy.NoShowWindowDays, count),
+		StartTime:   now,
+		EndTime:     now.AddDate(0, 0, policy.NoShowPenaltyDays),
+	}
+	if err := tx.Create(&penalty).Error; err != nil {
+		return errors.New("保存报名限制失败")
+	}
+	return nil
+}
+
+// MarkNoShows 定时任务：使用签到的活动结束后，仍未签到的已批准报名自动记为未到场。
+// 从未生成过签到码的活动不签到，不自动记未到场，由管理员手动登记考勤
+func MarkNoShows(ctx context.Context) (int64, error) {
+	var apps []model.Application
+	if err := config.DB.WithContext(ctx).Table("Application app").
+		Select("app.*").
+		Joins("JOIN Activity a ON app.activity_id = a.activity_id").
+		Where("app.current_status = ? AND DATE_ADD(a.activity_time, INTERVAL a.duration_minutes MINUTE) < NOW()", model.AppStatusApproved).
+		Where("EXISTS (SELECT 1 FROM CheckInCode c WHERE c.activity_id = a.activity_id AND c.purpose = ?)", model.CheckInPurposeIn).
+		Find(&apps).Error; err != nil {
+		return 0, errors.New("查询未签到报名失败")
+	}
+
+	var marked int64
+	for i := range apps {
+		if ctx.Err() != nil {
+			return marked, ctx.Err()
+		}
+		app := &apps[i]
+		err := transaction(func(tx *gorm.DB) error {
+			if err := changeApplicationStatus(tx, app, model.AppStatusNoShow, nil, "活动结束仍未签到"); err != nil {
+				return err
+			}
+			if err := setAttendanceStatus(tx, app, model.AttendanceAbsent); err != nil {
+				return err
+			}
+			return evaluateNoShowPenalty(tx, app.UserID)
+		})
+		if err != nil {
+			log.Printf("记录未到场失败 (报名ID:%d): %v", app.ApplicationID, err)
+			continue
+		}
+		marked++
+	}
+	return marked, nil
+}
+
+// ListPenalties 管理员查看所有用户的报名限制，activeOnly为true时只看生效中的
+func ListPenalties(handlerID int, activeOnly bool) ([]model.PenaltyInfo, error) {
+	if err := requireAdmin(handlerID, "查看报名限制"); err != nil {
+		return nil, err
+	}
+	return listPenalties(nil, activeOnly)
+}
+
+// ListUserPenalties 查看某用户自己的报名限制记录
+func ListUserPenalties(userID int) ([]model.PenaltyInfo, error) {
+	return listPenalties(&userID, false)
+}
+
+func listPenalties(userID *int, activeOnly bool) ([]model.PenaltyInfo, error) {
+	var penalties []model.PenaltyInfo
+	query := config.DB.Table("ParticipationPenalty p").
+		Select("p.*, u.username, (p.lifted_at IS NULL AND p.end_time > NOW()) AS active").
+		Joins("JOIN User u ON p.user_id = u.user_id")
+	if userID != nil {
+		query = query.Where("p.user_id = ?", *userID)
+	}
+	if activeOnly {
+		query = query.Where("p.lifted_at IS NULL AND p.end_time > NOW()")
+	}
+	if err := query.Order("p.start_time DESC").Scan(&penalties).Error; err != nil {
+		return nil, errors.New("查询报名限制失败")
+	}
+	return penalties, nil
+}
+
+// LiftPenalty 管理员提前解除报名限制
+func LiftPenalty(penaltyID int, req *model.LiftPenaltyRequest) error {
+	if err := requireAdmin(req.HandlerID, "解除报名限制"); err != nil {
+		return err
+	}
+
+	var penalty model.ParticipationPenalty
+	if err := config.DB.First(&penalty, "penalty_id = ?", penaltyID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("报名限制不存在")
+		}
+		return errors.New("查询报名限制失败")
+	}
+	if penalty.LiftedAt != nil {
+		return errors.New("该限制已解除")
+	}
+	if !penalty.EndTime.After(time.Now()) {
+		return errors.New("该限制已到期")
+	}
+
+	now := time.Now()
+	if err := config.DB.Model(&model.ParticipationPenalty{}).
+		Where("penalty_id = ?", penaltyID).
+		Updates(map[string]interface{}{
+			"lifted_at":   now,
+			"lifted_by":   req.HandlerID,
+			"lift_reason": strings.TrimSpace(req.Reason),
+		}).Error; err != nil {
+		return errors.New("解除报名限制失败")
+	}
+	return nil
+}
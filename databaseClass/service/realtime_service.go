@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/model"
+	"volunteer-system/realtime"
+
+	"gorm.io/gorm"
+)
+
+type eventBufferKey struct{}
+
+// eventBuffer 事务中产生的实时事件，提交后才推送
+type eventBuffer struct {
+	events []realtime.Event
+}
+
+func eventBufferOf(tx *gorm.DB) *eventBuffer {
+	if tx.Statement == nil || tx.Statement.Context == nil {
+		return nil
+	}
+	buf, _ := tx.Statement.Context.Value(eventBufferKey{}).(*eventBuffer)
+	return buf
+}
+
+// transaction 开启数据库事务，事务中产生的实时事件在提交成功后推送，回滚则丢弃
+func transaction(fn func(tx *gorm.DB) error) error {
+	buf := &eventBuffer{}
+	ctx := context.WithValue(context.Background(), eventBufferKey{}, buf)
+	if err := config.DB.WithContext(ctx).Transaction(fn); err != nil {
+		return err
+	}
+	dispatchEvents(buf.events)
+	return nil
+}
+
+// savepoint 在事务中开启保存点，保存点回滚时一并丢弃其中产生的实时事件
+func savepoint(tx *gorm.DB, fn func(tx *gorm.DB) error) error {
+	buf := eventBufferOf(tx)
+	mark := 0
+	if buf != nil {
+		mark = len(buf.events)
+	}
+	err := tx.Transaction(fn)
+	if err != nil && buf != nil {
+		buf.events = buf.events[:mark]
+	}
+	return err
+}
+
+// publishEvent 发布实时事件：在事务中时等提交后推送，否则立即推送
+func publishEvent(tx *gorm.DB, ev realtime.Event) {
+	if buf := eventBufferOf(tx); buf != nil {
+		buf.events = append(buf.events, ev)
+		return
+	}
+	dispatchEvents([]realtime.Event{ev})
+}
+
+// realtimeBatchSize 每次轮询读取的事件数上限
+const realtimeBatchSize = 500
+
+// dispatchEvents 将已提交的事件写入共享事件表，由各实例的 RunEventRelay 推送给连接在本实例上的客户端，
+// 这样后台任务（只在领导者实例上执行）产生的事件也能送达其他实例的连接；写入失败时只推送给本实例的连接
+func dispatchEvents(events []realtime.Event) {
+	if len(events) == 0 {
+		return
+	}
+	now := time.Now()
+	rows := make([]model.RealtimeEvent, 0, len(events))
+	for _, ev := range events {
+		payload, err := json.Marshal(ev.Data)
+		if err != nil {
+			log.Printf("[realtime] 序列化事件 %s 失败: %v", ev.Type, err)
+			continue
+		}
+		row := model.RealtimeEvent{
+			EventType: ev.Type,
+			Payload:   string(payload),
+			Admins:    ev.Admins,
+			Broadcast: ev.Broadcast,
+			CreatedAt: now,
+		}
+		if ev.UserID != 0 {
+			userID := ev.UserID
+			row.UserID = &userID
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return
+	}
+	if err := config.DB.Create(&rows).Error; err != nil {
+		log.Printf("[realtime] 写入共享事件表失败，只推送给本实例的连接: %v", err)
+		for _, ev := range events {
+			realtime.Publish(ev)
+		}
+	}
+}
+
+// RunEventRelay 每个实例都运行：轮询共享事件表，把所有实例写入的新事件推送给连接在本实例上的客户端，ctx取消后返回。
+// 事件ID由数据库自增分配，并发写入时较大的ID可能先提交，因此出现空缺的ID在 GapTimeout 内继续补查。
+func RunEventRelay(ctx context.Context) {
+	// 只推送本实例启动之后的事件
+	var cursor int64
+	if err := config.DB.WithContext(ctx).Model(&model.RealtimeEvent{}).
+		Select("COALESCE(MAX(event_id), 0)").Scan(&cursor).Error; err != nil {
+		log.Printf("[realtime] 查询事件起点失败: %v", err)
+	}
+	gaps := make(map[int64]time.Time)
+
+	ticker := time.NewTicker(config.Realtime.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var err error
+			if cursor, err = relayEvents(ctx, cursor, gaps); err != nil && ctx.Err() == nil {
+				log.Printf("[realtime] 读取共享事件失败: %v", err)
+			}
+		}
+	}
+}
+
+// relayEvents 读取游标之后的事件及仍在等待的空缺ID并推送，返回新的游标
+func relayEvents(ctx context.Context, cursor int64, gaps map[int64]time.Time) (int64, error) {
+	now := time.Now()
+	missing := make([]int64, 0, len(gaps))
+	for id, since := range gaps {
+		if now.Sub(since) > config.Realtime.GapTimeout {
+			delete(gaps, id)
+			continue
+		}
+		missing = append(missing, id)
+	}
+
+	query := config.DB.WithContext(ctx).Where("event_id > ?", cursor)
+	if len(missing) > 0 {
+		query = config.DB.WithContext(ctx).Where("event_id > ? OR event_id IN ?", cursor, missing)
+	}
+	var rows []model.RealtimeEvent
+	if err := query.Order("event_id ASC").Limit(realtimeBatchSize).Find(&rows).Error; err != nil {
+		return cursor, err
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if row.EventID > cursor {
+			// 空缺过大时多为自增值跳跃而非未提交的事务，不再等待
+			if row.EventID-cursor <= realtimeBatchSize {
+				for id := cursor + 1; id < row.EventID; id++ {
+					gaps[id] = now
+				}
+			}
+			cursor = row.EventID
+		}
+		delete(gaps, row.EventID)
+
+		ev := realtime.Event{
+			Type:      row.EventType,
+			Data:      json.RawMessage(row.Payload),
+			Admins:    row.Admins,
+			Broadcast: row.Broadcast,
+		}
+		if row.UserID != nil {
+			ev.UserID = *row.UserID
+		}
+		realtime.Publish(ev)
+	}
+	return cursor, nil
+}
+
+// PruneRealtimeEvents 删除超过保留时间的共享事件
+func PruneRealtimeEvents(ctx context.Context) (int64, error) {
+	result := config.DB.WithContext(ctx).
+		Where("created_at < ?", time.Now().Add(-config.Realtime.Retention)).
+		Delete(&model.RealtimeEvent{})
+	if result.Error != nil {
+		return 0, errors.New("清理实时事件失败")
+	}
+	return result.RowsAffected, nil
+}
+
+// publishActivitySlots 广播活动报名人数变化，口径与可申请活动列表的剩余名额一致
+func publishActivitySlots(tx *gorm.DB, activityID int) error {
+	var activity model.Activity
+	if err := tx.Select("activity_id", "max_people").
+		First(&activity, "activity_id = ?", activityID).Error; err != nil {
+		return errors.New("查询活动失败")
+	}
+	var applyCount int64
+	if err := tx.Model(&model.Application{}).
+		Where("activity_id = ? AND current_status NOT IN ?", activityID, releasedStatuses).
+		Count(&applyCount).Error; err != nil {
+		return errors.New("查询活动报名人数失败")
+	}
+	slotHolders, err := countSlotHolders(tx, activityID)
+	if err != nil {
+		return err
+	}
+
+	publishEvent(tx, realtime.Event{
+		Type:      realtime.EventActivitySlots,
+		Broadcast: true,
+		Data: map[string]interface{}{
+			"activity_id":         activityID,
+			"max_people":          activity.MaxPeople,
+			"current_apply_count": applyCount,
+			"remaining_slots":     activity.MaxPeople - int(applyCount),
+			"slot_holders":        slotHolders,
+		},
+	})
+	return nil
+}
+
+// SubscribeEvents 为用户订阅实时事件，管理员同时接收新报名和全部报名状态变更
+func SubscribeEvents(userID int) (*realtime.Subscriber, error) {
+	var count int64
+	if err := config.DB.Model(&model.User{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, errors.New("查询用户失败")
+	}
+	if count == 0 {
+		return nil, errors.New("用户不存在")
+	}
+	admin, err := isAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+	return realtime.Subscribe(userID, admin), nil
+}
+
+// UnsubscribeEvents 取消实时事件订阅
+func UnsubscribeEvents(s *realtime.Subscriber) {
+	realtime.Unsubscribe(s)
+}
+
+// applicationEventData 报名相关事件的数据
+func applicationEventData(app *model.Application) map[string]interface{} {
+	return map[string]interface{}{
+		"application_id": app.ApplicationID,
+		"activity_id":    app.ActivityID,
+		"user_id":        app.UserID,
+		"status":         app.CurrentStatus,
+		"at":             time.Now(),
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/mailer"
+	"volunteer-system/model"
+
+	"gorm.io/gorm"
+)
+
+// SendActivityReminders 定时任务：在活动开始前的各提醒时间点通知已批准的志愿者，
+// 并向组织者发送名单汇总。提醒记录与站内通知在同一事务中写入，
+// 提醒记录的唯一键保证每条提醒只发送一次，返回发送的提醒数
+func SendActivityReminders(ctx context.Context) (int64, error) {
+	sent, err := sendVolunteerReminders(ctx)
+	if err != nil {
+		return sent, err
+	}
+	rosters, err := sendRosterSummaries(ctx)
+	return sent + rosters, err
+}
+
+func offsetMinutes(d time.Duration) int {
+	return int(d / time.Minute)
+}
+
+// sendVolunteerReminders 活动进入某个提醒时间点后提醒已批准的志愿者；
+// 同时进入多个时间点（如临近开始才批准）只发一条提醒，但每个时间点都记为已发送
+func sendVolunteerReminders(ctx context.Context) (int64, error) {
+	offsets := config.Policy.ReminderOffsets
+	if len(offsets) == 0 {
+		return 0, nil
+	}
+	var maxOffset time.Duration
+	for _, d := range offsets {
+		maxOffset = max(maxOffset, d)
+	}
+
+	now := time.Now()
+	var apps []struct {
+		model.Application
+		Title        string
+		Location     string
+		ActivityTime time.Time
+	}
+	if err := config.DB.WithContext(ctx).Table("Application app").
+		Select("app.*, a.title, a.location, a.activity_time").
+		Joins("JOIN Activity a ON app.activity_id = a.activity_id").
+		Where("app.current_status = ? AND a.status IN ?", model.AppStatusApproved, publishedActivityStatuses).
+		Where("a.activity_time > ? AND a.activity_time <= ?", now, now.Add(maxOffset)).
+		Scan(&apps).Error; err != nil {
+		return 0, errors.New("查询待提醒的报名失败")
+	}
+	if len(apps) == 0 {
+		return 0, nil
+	}
+
+	appIDs := make([]int, 0, len(apps))
+	for _, app := range apps {
+		appIDs = append(appIDs, app.ApplicationID)
+	}
+	var logs []model.ReminderLog
+	if err := config.DB.WithContext(ctx).
+		Where("reminder_type = ? AND application_id IN ?", model.ReminderTypeVolunteer, appIDs).
+		Find(&logs).Error; err != nil {
+		return 0, errors.New("查询提醒记录失败")
+	}
+	sentOffsets := make(map[int]map[int]bool)
+	for _, l := range logs {
+		if sentOffsets[*l.ApplicationID] == nil {
+			sentOffsets[*l.ApplicationID] = make(map[int]bool)
+		}
+		sentOffsets[*l.ApplicationID][l.OffsetMinutes] = true
+	}
+
+	var sent int64
+	for i := range apps {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+		app := &apps[i]
+
+		remaining := app.ActivityTime.Sub(now)
+		var due []int
+		for _, d := range offsets {
+			if d >= remaining && !sentOffsets[app.ApplicationID][offsetMinutes(d)] {
+				due = append(due, offsetMinutes(d))
+			}
+		}
+		if len(due) == 0 {
+			continue
+		}
+
+		err := transaction(func(tx *gorm.DB) error {
+			for _, minutes := range due {
+				reminder := model.ReminderLog{
+					ReminderType:  model.ReminderTypeVolunteer,
+					ActivityID:    app.ActivityID,
+					UserID:        app.UserID,
+					ApplicationID: &app.ApplicationID,
+					OffsetMinutes: minutes,
+					SentAt:        now,
+				}
+				if err := tx.Create(&reminder).Error; err != nil {
+					return err
+				}
+			}
+			activityTime := app.ActivityTime.Format("2006-01-02 15:04")
+			if err := notifyUser(tx, app.UserID, "活动提醒", fmt.Sprintf("您报名的活动“%s”将于%s在%s开始，请准时参加",
+				app.Title, activityTime, app.Location), &app.ActivityID, &app.ApplicationID); err != nil {
+				return err
+			}
+			return enqueueEmail(tx, app.UserID, mailer.TemplateActivityReminder, map[string]interface{}{
+				"ActivityTitle": app.Title,
+				"ActivityTime":  activityTime,
+				"Location":      app.Location,
+			})
+		})
+		if err != nil {
+			log.Printf("发送活动提醒失败 (报名ID:%d): %v", app.ApplicationID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// sendRosterSummaries 活动开始前向组织者发送一次已批准志愿者名单
+func sendRosterSummaries(ctx context.Context) (int64, error) {
+	offset := config.Policy.RosterSummaryOffset
+	if offset <= 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	var activities []model.Activity
+	if err := config.DB.WithContext(ctx).
+		Where("status IN ? AND activity_time > ? AND activity_time <= ?", publishedActivityStatuses, now, now.Add(offset)).
+		Where("activity_id NOT IN (SELECT activity_id FROM ReminderLog WHERE reminder_type = ?)", model.ReminderTypeRoster).
+		Find(&activities).Error; err != nil {
+		return 0, errors.New("查询待发送名单汇总的活动失败")
+	}
+
+	var sent int64
+	for i := range activities {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+		activity := &activities[i]
+
+		var names []string
+		if err := config.DB.WithContext(ctx).Table("Application app").
+			Joins("JOIN User u ON app.user_id = u.user_id").
+			Where("app.activity_id = ? AND app.current_status = ?", activity.ActivityID, model.AppStatusApproved).
+			Order("app.apply_time ASC").
+			Pluck("u.username", &names).Error; err != nil {
+			log.Printf("查询活动名单失败 (活动ID:%d): %v", activity.ActivityID, err)
+			continue
+		}
+
+		roster := "暂无已批准的志愿者"
+		if len(names) > 0 {
+			roster = strings.Join(names, "、")
+		}
+
+		err := transaction(func(tx *gorm.DB) error {
+			reminder := model.ReminderLog{
+				ReminderType:  model.ReminderTypeRoster,
+				ActivityID:    activity.ActivityID,
+				UserID:        activity.CreatorID,
+				OffsetMinutes: offsetMinutes(offset),
+				SentAt:        now,
+			}
+			if err := tx.Create(&reminder).Error; err != nil {
+				return err
+			}
+			return notifyUser(tx, activity.CreatorID, "活动名单汇总", fmt.Sprintf("活动“%s”将于%s开始，已批准%d人（名额%d）：%s",
+				activity.Title, activity.ActivityTime.Format("2006-01-02 15:04"), len(names), activity.MaxPeople, roster),
+				&activity.ActivityID, nil)
+		})
+		if err != nil {
+			log.Printf("发送名单汇总失败 (活动ID:%d): %v", activity.ActivityID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
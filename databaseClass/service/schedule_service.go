@@ -1,51 +1,77 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"time"
 
 	"volunteer-system/config"
 	"volunteer-system/model"
+
+	"gorm.io/gorm"
 )
 
-// CloseExpiredActivities 定时任务：检查并关闭过期的活动
-func CloseExpiredActivities() {
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
+// ExpireActivities 定时任务：将活动时间已过的活动（待发布、报名中、报名已关闭）批量标记为已过期，
+// 并将已过期活动中仍待审核或候补的报名变更为已过期，返回更新的活动数与报名数之和
+func ExpireActivities(ctx context.Context) (int64, error) {
+	result := config.DB.WithContext(ctx).Model(&model.Activity{}).
+		Where("status IN ? AND activity_time < ?", []string{
+			model.ActivityStatusScheduled, model.ActivityStatusActive, model.ActivityStatusRegistrationClosed,
+		}, time.Now()).
+		Update("status", model.ActivityStatusExpired)
+	if result.Error != nil {
+		return 0, errors.New("更新过期活动失败")
+	}
 
-	for range ticker.C {
-		now := time.Now()
+	resolved, err := expireUnresolvedApplications(ctx)
+	return result.RowsAffected + resolved, err
+}
 
-		// 查询所有状态为active的活动
-		var activities []model.Activity
-		if err := config.DB.Where("status = ?", "active").Find(&activities).Error; err != nil {
-			log.Printf("查询活动失败: %v", err)
-			continue
-		}
+// expireUnresolvedApplications 已过期活动中未审核的报名由系统标记为已过期（状态变更时通知报名者）
+func expireUnresolvedApplications(ctx context.Context) (int64, error) {
+	var apps []model.Application
+	if err := config.DB.WithContext(ctx).Table("Application app").
+		Select("app.*").
+		Joins("JOIN Activity a ON app.activity_id = a.activity_id").
+		Where("a.status = ? AND app.current_status IN ?", model.ActivityStatusExpired,
+			[]string{model.AppStatusPending, model.AppStatusWaitlisted}).
+		Scan(&apps).Error; err != nil {
+		return 0, errors.New("查询过期活动的未审核报名失败")
+	}
 
-		// 逐个检查活动是否过期
-		for _, activity := range activities {
-			if activity.ActivityTime.Before(now) {
-				// 更新状态为expired
-				if err := config.DB.Model(&model.Activity{}).
-					Where("activity_id = ?", activity.ActivityID).
-					Update("status", "expired").Error; err != nil {
-					log.Printf("更新活动状态失败 (ID:%d): %v", activity.ActivityID, err)
-				} else {
-					log.Printf("活动已过期，自动关闭 (ID:%d, 标题:%s)", activity.ActivityID, activity.Title)
-				}
-			}
+	var expired int64
+	var errs []error
+	for i := range apps {
+		if ctx.Err() != nil {
+			return expired, ctx.Err()
+		}
+		app := &apps[i]
+		err := transaction(func(tx *gorm.DB) error {
+			return changeApplicationStatus(tx, app, model.AppStatusExpired, nil, "活动已过期，报名未获审核")
+		})
+		if err != nil {
+			log.Printf("标记过期报名失败 (报名ID:%d): %v", app.ApplicationID, err)
+			errs = append(errs, fmt.Errorf("报名ID %d: %w", app.ApplicationID, err))
+			continue
 		}
+		expired++
+	}
+	// 部分报名失败时任务记为失败，已成功的变更保留
+	if len(errs) > 0 {
+		return expired, fmt.Errorf("%d 条报名标记过期失败: %w", len(errs), errors.Join(errs...))
 	}
+	return expired, nil
 }
 
-// GetActiveActivities 获取所有活跃的活动（用户视角，不显示已过期的）
+// GetActiveActivities 获取所有已发布的活动（用户视角，不显示待发布和已过期的）
 func GetActiveActivities(deptID, categoryID *int) ([]model.Activity, error) {
 	var activities []model.Activity
 	query := config.DB.Model(&model.Activity{})
 
-	// 只查询状态为active的活动
-	query = query.Where("status = ?", "active")
+	// 只查询已发布的活动
+	query = query.Where("status IN ?", publishedActivityStatuses)
 
 	if deptID != nil {
 		query = query.Where("dept_id = ?", *deptID)
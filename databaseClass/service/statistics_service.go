@@ -8,14 +8,15 @@ import (
 )
 
 type StatisticsData struct {
-	TotalActivities      int64 `json:"total_activities"`
-	TotalUsers           int64 `json:"total_users"`
-	TotalApplications    int64 `json:"total_applications"`
-	ApprovedApplications int64 `json:"approved_applications"`
-	PendingApplications  int64 `json:"pending_applications"`
-	RejectedApplications int64 `json:"rejected_applications"`
-	ActiveActivities     int64 `json:"active_activities"`
-	ExpiredActivities    int64 `json:"expired_activities"`
+	TotalActivities       int64 `json:"total_activities"`
+	TotalUsers            int64 `json:"total_users"`
+	TotalApplications     int64 `json:"total_applications"`
+	ApprovedApplications  int64 `json:"approved_applications"`
+	PendingApplications   int64 `json:"pending_applications"`
+	RejectedApplications  int64 `json:"rejected_applications"`
+	WithdrawnApplications int64 `json:"withdrawn_applications"`
+	ActiveActivities      int64 `json:"active_activities"`
+	ExpiredActivities     int64 `json:"expired_activities"`
 }
 
 // 按部门统计
@@ -25,24 +26,29 @@ type DeptStatistics struct {
 	ActivityCount int     `json:"activity_count"`
 	AvgCapacity   float64 `json:"avg_capacity"`
 	CreatorCount  int     `json:"creator_count"`
+	AvgRating     float64 `json:"avg_rating"`
+	RatingCount   int     `json:"rating_count"`
 }
 
 // 按分类统计
 type CategoryStatistics struct {
-	CategoryID        int    `json:"category_id"`
-	CategoryName      string `json:"category_name"`
-	ActivityCount     int    `json:"activity_count"`
-	TotalApplications int    `json:"total_applications"`
-	ApprovedCount     int    `json:"approved_count"`
+	CategoryID        int     `json:"category_id"`
+	CategoryName      string  `json:"category_name"`
+	ActivityCount     int     `json:"activity_count"`
+	TotalApplications int     `json:"total_applications"`
+	ApprovedCount     int     `json:"approved_count"`
+	AvgRating         float64 `json:"avg_rating"`
+	RatingCount       int     `json:"rating_count"`
 }
 
 // 用户活跃度统计
 type UserActivityStatistics struct {
-	UserID        int    `json:"user_id"`
-	Username      string `json:"username"`
-	TotalApplied  int    `json:"total_applied"`
-	ApprovedCount int    `json:"approved_count"`
-	RejectedCount int    `json:"rejected_count"`
+	UserID         int    `json:"user_id"`
+	Username       string `json:"username"`
+	TotalApplied   int    `json:"total_applied"`
+	ApprovedCount  int    `json:"approved_count"`
+	RejectedCount  int    `json:"rejected_count"`
+	WithdrawnCount int    `json:"withdrawn_count"`
 }
 
 // 活动热度排行
@@ -52,6 +58,7 @@ type ActivityPopularity struct {
 	MaxPeople        int     `json:"max_people"`
 	ApplicationCount int     `json:"application_count"`
 	ApprovedCount    int     `json:"approved_count"`
+	WithdrawnCount   int     `json:"withdrawn_count"`
 	FillRate         float64 `json:"fill_rate"`
 }
 
@@ -110,9 +117,9 @@ func GetStatistics() (*StatisticsData, error) {
 		return nil, errors.New("查询总报名数失败")
 	}
 
-	// 已批准报名数
+	// 已批准报名数（含已参加）
 	if err := config.DB.Model(&model.Application{}).
-		Where("current_status = ?", "approved").
+		Where("current_status IN ?", slotHoldingStatuses).
 		Count(&stats.ApprovedApplications).Error; err != nil {
 		return nil, errors.New("查询已批准报名数失败")
 	}
@@ -131,9 +138,16 @@ func GetStatistics() (*StatisticsData, error) {
 		return nil, errors.New("查询已拒绝报名数失败")
 	}
 
-	// 活跃活动数
+	// 已撤回报名数
+	if err := config.DB.Model(&model.Application{}).
+		Where("current_status = ?", model.AppStatusWithdrawn).
+		Count(&stats.WithdrawnApplications).Error; err != nil {
+		return nil, errors.New("查询已撤回报名数失败")
+	}
+
+	// 活跃活动数（已发布未过期，含报名已关闭的活动）
 	if err := config.DB.Model(&model.Activity{}).
-		Where("status = ?", "active").
+		Where("status IN ?", publishedActivityStatuses).
 		Count(&stats.ActiveActivities).Error; err != nil {
 		return nil, errors.New("查询活跃活动数失败")
 	}
@@ -156,7 +170,12 @@ func GetDeptStatistics() ([]DeptStatistics, error) {
 		SELECT d.dept_id, d.dept_name,
 			COUNT(DISTINCT a.activity_id) as activity_count,
 			IFNULL(AVG(a.max_people), 0) as avg_capacity,
-			COUNT(DISTINCT a.creator_id) as creator_count
+			COUNT(DISTINCT a.creator_id) as creator_count,
+			-- 评分用子查询统计，避免与活动JOIN后重复计数
+			COALESCE((SELECT ROUND(AVG(f.rating), 2) FROM ActivityFeedback f
+				JOIN Activity fa ON f.activity_id = fa.activity_id WHERE fa.dept_id = d.dept_id), 0) as avg_rating,
+			(SELECT COUNT(*) FROM ActivityFeedback f
+				JOIN Activity fa ON f.activity_id = fa.activity_id WHERE fa.dept_id = d.dept_id) as rating_count
 		FROM Dept d
 		LEFT JOIN Activity a ON d.dept_id = a.dept_id
 		GROUP BY d.dept_id, d.dept_name
@@ -178,13 +197,17 @@ func GetCategoryStatistics() ([]CategoryStatistics, error) {
 		SELECT ac.category_id, ac.category_name,
 			COUNT(DISTINCT a.activity_id) as activity_count,
 			COALESCE(COUNT(ap.application_id), 0) as total_applications,
-			COALESCE(SUM(CASE WHEN ap.current_status = 'approved' THEN 1 ELSE 0 END), 0) as approved_count
+			COALESCE(SUM(CASE WHEN ap.current_status IN ? THEN 1 ELSE 0 END), 0) as approved_count,
+			COALESCE((SELECT ROUND(AVG(f.rating), 2) FROM ActivityFeedback f
+				JOIN Activity fa ON f.activity_id = fa.activity_id WHERE fa.category_id = ac.category_id), 0) as avg_rating,
+			(SELECT COUNT(*) FROM ActivityFeedback f
+				JOIN Activity fa ON f.activity_id = fa.activity_id WHERE fa.category_id = ac.category_id) as rating_count
 		FROM ActivityCategory ac
 		LEFT JOIN Activity a ON ac.category_id = a.category_id
 		LEFT JOIN Application ap ON a.activity_id = ap.activity_id
 		GROUP BY ac.category_id, ac.category_name
 		ORDER BY activity_count DESC
-	`).Scan(&results).Error
+	`, slotHoldingStatuses).Scan(&results).Error
 
 	if err != nil {
 		return nil, errors.New("查询分类统计失败")
@@ -200,14 +223,15 @@ func GetUserActivityStatistics() ([]UserActivityStatistics, error) {
 	err := config.DB.Raw(`
 		SELECT u.user_id, u.username,
 			COUNT(ap.application_id) as total_applied,
-			COALESCE(SUM(CASE WHEN ap.current_status = 'approved' THEN 1 ELSE 0 END), 0) as approved_count,
-			COALESCE(SUM(CASE WHEN ap.current_status = 'rejected' THEN 1 ELSE 0 END), 0) as rejected_count
+			COALESCE(SUM(CASE WHEN ap.current_status IN ? THEN 1 ELSE 0 END), 0) as approved_count,
+			COALESCE(SUM(CASE WHEN ap.current_status = 'rejected' THEN 1 ELSE 0 END), 0) as rejected_count,
+			COALESCE(SUM(CASE WHEN ap.current_status = 'withdrawn' THEN 1 ELSE 0 END), 0) as withdrawn_count
 		FROM User u
 		LEFT JOIN Application ap ON u.user_id = ap.user_id
 		GROUP BY u.user_id, u.username
 		HAVING COUNT(ap.application_id) > 0
 		ORDER BY approved_count DESC
-	`).Scan(&results).Error
+	`, slotHoldingStatuses).Scan(&results).Error
 
 	if err != nil {
 		return nil, errors.New("查询用户活跃度统计失败")
@@ -222,15 +246,17 @@ func GetActivityPopularity() ([]ActivityPopularity, error) {
 
 	err := config.DB.Raw(`
 		SELECT a.activity_id, a.title, a.max_people,
-			COALESCE(COUNT(ap.application_id), 0) as application_count,
-			COALESCE(SUM(CASE WHEN ap.current_status = 'approved' THEN 1 ELSE 0 END), 0) as approved_count,
-			ROUND(COALESCE(COUNT(ap.application_id), 0) / a.max_people * 100, 2) as fill_rate
+			-- 已撤回的报名不计入报名数和报名率
+			COALESCE(SUM(CASE WHEN ap.current_status <> 'withdrawn' THEN 1 ELSE 0 END), 0) as application_count,
+			COALESCE(SUM(CASE WHEN ap.current_status IN ? THEN 1 ELSE 0 END), 0) as approved_count,
+			COALESCE(SUM(CASE WHEN ap.current_status = 'withdrawn' THEN 1 ELSE 0 END), 0) as withdrawn_count,
+			ROUND(COALESCE(SUM(CASE WHEN ap.current_status <> 'withdrawn' THEN 1 ELSE 0 END), 0) / a.max_people * 100, 2) as fill_rate
 		FROM Activity a
 		LEFT JOIN Application ap ON a.activity_id = ap.activity_id
 		GROUP BY a.activity_id, a.title, a.max_people
 		ORDER BY application_count DESC
 		LIMIT 10
-	`).Scan(&results).Error
+	`, slotHoldingStatuses).Scan(&results).Error
 
 	if err != nil {
 		return nil, errors.New("查询活动热度排行失败")
@@ -247,14 +273,14 @@ func GetAdminCreationStatistics() ([]AdminCreationStatistics, error) {
 		SELECT u.user_id, u.username,
 			COUNT(DISTINCT a.activity_id) as created_activities,
 			COALESCE(COUNT(ap.application_id), 0) as total_applications,
-			COALESCE(SUM(CASE WHEN ap.current_status = 'approved' THEN 1 ELSE 0 END), 0) as approved_applications
+			COALESCE(SUM(CASE WHEN ap.current_status IN ? THEN 1 ELSE 0 END), 0) as approved_applications
 		FROM User u
 		LEFT JOIN Activity a ON u.user_id = a.creator_id
 		LEFT JOIN Application ap ON a.activity_id = ap.activity_id
 		WHERE u.role_id = 2
 		GROUP BY u.user_id, u.username
 		ORDER BY created_activities DESC
-	`).Scan(&results).Error
+	`, slotHoldingStatuses).Scan(&results).Error
 
 	if err != nil {
 		return nil, errors.New("查询管理员创建统计失败")
@@ -278,7 +304,7 @@ func GetUpcomingActivities() ([]UpcomingActivity, error) {
 		LEFT JOIN ActivityCategory ac ON a.category_id = ac.category_id
 		WHERE a.activity_time > NOW()
 			AND a.activity_time <= DATE_ADD(NOW(), INTERVAL 3 DAY)
-			AND a.status = 'active'
+			AND a.status IN ('active', 'registration_closed')
 		ORDER BY a.activity_time ASC
 	`).Scan(&results).Error
 
@@ -299,25 +325,25 @@ func GetPopularActivities() ([]PopularActivity, error) {
 			COALESCE(ac.category_name, '') as category_name, 
 			COALESCE(u.username, '') as creator_name,
 			a.max_people,
-			COALESCE(SUM(CASE WHEN ap.current_status = 'approved' THEN 1 ELSE 0 END), 0) as approved_count,
+			COALESCE(SUM(CASE WHEN ap.current_status IN ? THEN 1 ELSE 0 END), 0) as approved_count,
 			COALESCE(SUM(CASE WHEN ap.current_status = 'pending' THEN 1 ELSE 0 END), 0) as pending_count,
 			COALESCE(SUM(CASE WHEN ap.current_status = 'rejected' THEN 1 ELSE 0 END), 0) as rejected_count,
-			ROUND(COALESCE(SUM(CASE WHEN ap.current_status = 'approved' THEN 1 ELSE 0 END), 0) / a.max_people * 100, 2) as fill_rate
+			ROUND(COALESCE(SUM(CASE WHEN ap.current_status IN ? THEN 1 ELSE 0 END), 0) / a.max_people * 100, 2) as fill_rate
 		FROM Activity a
 		LEFT JOIN Dept d ON a.dept_id = d.dept_id
 		LEFT JOIN ActivityCategory ac ON a.category_id = ac.category_id
 		LEFT JOIN User u ON a.creator_id = u.user_id
 		LEFT JOIN Application ap ON a.activity_id = ap.activity_id
-		WHERE a.status = 'active'
+		WHERE a.status IN ('active', 'registration_closed')
 			AND EXISTS (
 				SELECT 1 FROM Application ap2
 				WHERE a.activity_id = ap2.activity_id 
-				AND ap2.current_status = 'approved'
+				AND ap2.current_status IN ?
 			)
 		GROUP BY a.activity_id, a.title, a.max_people, a.dept_id, a.category_id, a.creator_id
 		ORDER BY approved_count DESC
 		LIMIT 10
-	`).Scan(&results).Error
+	`, slotHoldingStatuses, slotHoldingStatuses, slotHoldingStatuses).Scan(&results).Error
 
 	if err != nil {
 		return nil, errors.New("查询热门活动失败: " + err.Error())
@@ -400,12 +426,12 @@ func GetOmnipotentVolunteers() ([]OmnipotentVolunteer, error) {
 			(SELECT COUNT(*) FROM ActivityCategory) as total_categories_count,
 			COUNT(DISTINCT app.application_id) as approved_count
 		FROM User u
-		JOIN Application app ON u.user_id = app.user_id AND app.current_status = 'approved'
+		JOIN Application app ON u.user_id = app.user_id AND app.current_status IN ?
 		JOIN Activity a ON app.activity_id = a.activity_id
 		GROUP BY u.user_id, u.username
 		HAVING COUNT(DISTINCT a.category_id) = (SELECT COUNT(*) FROM ActivityCategory)
 		ORDER BY approved_count DESC
-	`).Scan(&results).Error
+	`, slotHoldingStatuses).Scan(&results).Error
 
 	if err != nil {
 		return nil, errors.New("查询全能志愿者失败")
@@ -413,3 +439,96 @@ func GetOmnipotentVolunteers() ([]OmnipotentVolunteer, error) {
 
 	return results, nil
 }
+
+// UserHoursStatistics 按用户统计服务时长
+type UserHoursStatistics struct {
+	UserID        int     `json:"user_id"`
+	Username      string  `json:"username"`
+	DeptName      string  `json:"dept_name"`
+	ActivityCount int     `json:"activity_count"`
+	TotalHours    float64 `json:"total_hours"`
+}
+
+// DeptHoursStatistics 按活动所属部门统计服务时长
+type DeptHoursStatistics struct {
+	DeptID         int     `json:"dept_id"`
+	DeptName       string  `json:"dept_name"`
+	VolunteerCount int     `json:"volunteer_count"`
+	TotalHours     float64 `json:"total_hours"`
+}
+
+// CategoryHoursStatistics 按活动分类统计服务时长
+type CategoryHoursStatistics struct {
+	CategoryID     int     `json:"category_id"`
+	CategoryName   string  `json:"category_name"`
+	VolunteerCount int     `json:"volunteer_count"`
+	TotalHours     float64 `json:"total_hours"`
+}
+
+// GetUserHoursStatistics 按用户统计服务时长（聚合函数+GROUP BY+HAVING）
+func GetUserHoursStatistics() ([]UserHoursStatistics, error) {
+	var results []UserHoursStatistics
+
+	err := config.DB.Raw(`
+		SELECT u.user_id, u.username,
+			COALESCE(d.dept_name, '') as dept_name,
+			COUNT(DISTINCT h.activity_id) as activity_count,
+			ROUND(SUM(h.hours), 2) as total_hours
+		FROM User u
+		JOIN ServiceHoursEntry h ON u.user_id = h.user_id
+		LEFT JOIN Dept d ON u.dept_id = d.dept_id
+		GROUP BY u.user_id, u.username, d.dept_name
+		HAVING SUM(h.hours) > 0
+		ORDER BY total_hours DESC
+	`).Scan(&results).Error
+
+	if err != nil {
+		return nil, errors.New("查询用户服务时长统计失败")
+	}
+
+	return results, nil
+}
+
+// GetDeptHoursStatistics 按部门统计服务时长（只统计关联了活动的记录）
+func GetDeptHoursStatistics() ([]DeptHoursStatistics, error) {
+	var results []DeptHoursStatistics
+
+	err := config.DB.Raw(`
+		SELECT d.dept_id, d.dept_name,
+			COUNT(DISTINCT h.user_id) as volunteer_count,
+			COALESCE(ROUND(SUM(h.hours), 2), 0) as total_hours
+		FROM Dept d
+		LEFT JOIN Activity a ON d.dept_id = a.dept_id
+		LEFT JOIN ServiceHoursEntry h ON a.activity_id = h.activity_id
+		GROUP BY d.dept_id, d.dept_name
+		ORDER BY total_hours DESC
+	`).Scan(&results).Error
+
+	if err != nil {
+		return nil, errors.New("查询部门服务时长统计失败")
+	}
+
+	return results, nil
+}
+
+// GetCategoryHoursStatistics 按分类统计服务时长（只统计关联了活动的记录）
+func GetCategoryHoursStatistics() ([]CategoryHoursStatistics, error) {
+	var results []CategoryHoursStatistics
+
+	err := config.DB.Raw(`
+		SELECT ac.category_id, ac.category_name,
+			COUNT(DISTINCT h.user_id) as volunteer_count,
+			COALESCE(ROUND(SUM(h.hours), 2), 0) as total_hours
+		FROM ActivityCategory ac
+		LEFT JOIN Activity a ON ac.category_id = a.category_id
+		LEFT JOIN ServiceHoursEntry h ON a.activity_id = h.activity_id
+		GROUP BY ac.category_id, ac.category_name
+		ORDER BY total_hours DESC
+	`).Scan(&results).Error
+
+	if err != nil {
+		return nil, errors.New("查询分类服务时长统计失败")
+	}
+
+	return results, nil
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errTeamApproval 团队报名的成员不能单独批准
+var errTeamApproval = errors.New("团队报名须通过团队审核整体批准")
+
+// memberError 在错误信息前加上成员用户名，保留原错误（错误码）
+func memberError(username string, err error) error {
+	return fmt.Errorf("成员 %s：%w", username, err)
+}
+
+// ApplyAsTeam 队长代表团队报名：每个成员各生成一条关联的报名记录，整体占用名额，任一成员不满足条件则整体失败
+func ApplyAsTeam(activityID int, req *model.TeamApplyRequest) (*model.TeamApplicationInfo, error) {
+	activity, err := loadOpenActivity(activityID)
+	if err != nil {
+		return nil, err
+	}
+	if activity.ApprovalMode == model.ApprovalModeLottery {
+		return nil, errors.New("抽签活动不支持团队报名")
+	}
+
+	team, err := findTeam(req.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	if team.LeaderID != req.UserID {
+		return nil, errors.New("只有队长可以代表团队报名")
+	}
+
+	members, err := listTeamMembers(team.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 逐个成员做报名前检查；自动批准须全体成员都满足条件
+	applicants := make([]*applicant, 0, len(members))
+	autoApprove := activity.ApprovalMode != model.ApprovalModeManual
+	// 每个成员保留各自的自动批准说明
+	autoComments := make(map[int]string, len(members))
+	for _, m := range members {
+		answers, ok := req.MemberAnswers[m.UserID]
+		if !ok {
+			answers = req.Answers
+		}
+		a, err := prepareApplicant(m.UserID, activity, answers)
+		if err != nil {
+			return nil, memberError(m.Username, err)
+		}
+		applicants = append(applicants, a)
+
+		if autoApprove {
+			approve, comment, err := autoApproval(m.UserID, activity)
+			if err != nil {
+				return nil, err
+			}
+			autoApprove = approve
+			autoComments[m.UserID] = comment
+		}
+	}
+
+	var teamApp model.TeamApplication
+	err = transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(activity, "activity_id = ?", activityID).Error; err != nil {
+			return errors.New("查询活动信息失败")
+		}
+
+		holders, err := countSlotHolders(tx, activityID)
+		if err != nil {
+			return err
+		}
+		if remaining := activity.MaxPeople - int(holders); remaining < len(applicants) {
+			return fmt.Errorf("活动剩余名额不足（剩余 %d 个，团队 %d 人）", max(remaining, 0), len(applicants))
+		}
+
+		teamApp = model.TeamApplication{
+			TeamID:      team.TeamID,
+			ActivityID:  activityID,
+			ApplicantID: req.UserID,
+			ApplyTime:   time.Now(),
+		}
+		if err := tx.Create(&teamApp).Error; err != nil {
+			return errors.New("保存团队报名失败")
+		}
+
+		for _, a := range applicants {
+			app, err := submitApplication(tx, activity, a, false, &teamApp.TeamApplicationID)
+			if err != nil {
+				return memberError(a.Username, err)
+			}
+			if autoApprove {
+				if err := changeApplicationStatus(tx, app, model.AppStatusApproved, nil, autoComments[a.UserID]); err != nil {
+					return memberError(a.Username, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetTeamApplication(teamApp.TeamApplicationID)
+}
+
+// GetTeamApplication 查询团队报名及各成员的报名状态
+func GetTeamApplication(teamApplicationID int) (*model.TeamApplicationInfo, error) {
+	var info model.TeamApplicationInfo
+	if err := config.DB.Table("TeamApplication ta").
+		Select("ta.*, t.name AS team_name, a.title AS activity_title").
+		Joins("JOIN Team t ON ta.team_id = t.team_id").
+		Joins("JOIN Activity a ON ta.activity_id = a.activity_id").
+		Where("ta.team_application_id = ?", teamApplicationID).
+		Take(&info).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("团队报名不存在")
+		}
+		return nil, errors.New("查询团队报名失败")
+	}
+
+	if err := config.DB.Table("Application").
+		Select("Application.application_id, Application.user_id, User.username, Application.apply_time, Application.current_status, Application.answers, Application.attendance_status, Application.team_application_id").
+		Joins("JOIN User ON Application.user_id = User.user_id").
+		Where("Application.team_application_id = ?", teamApplicationID).
+		Order("Application.application_id ASC").
+		Scan(&info.Applications).Error; err != nil {
+		return nil, errors.New("查询成员报名失败")
+	}
+	return &info, nil
+}
+
+// loadTeamApplicationMembers 查询团队报名中未释放名额的成员报名及用户名
+func loadTeamApplicationMembers(tx *gorm.DB, teamApplicationID int) ([]model.Application, map[int]string, error) {
+	var apps []model.Application
+	if err := tx.Where("team_application_id = ? AND current_status NOT IN ?", teamApplicationID, releasedStatuses).
+		Order("application_id ASC").
+		Find(&apps).Error; err != nil {
+		return nil, nil, errors.New("查询成员报名失败")
+	}
+
+	userIDs := make([]int, 0, len(apps))
+	for _, app := range apps {
+		userIDs = append(userIDs, app.UserID)
+	}
+	var users []model.User
+	if len(userIDs) > 0 {
+		if err := tx.Where("user_id IN ?", userIDs).Find(&users).Error; err != nil {
+			return nil, nil, errors.New("查询用户失败")
+		}
+	}
+	names := make(map[int]string, len(users))
+	for _, u := range users {
+		names[u.UserID] = u.Username
+	}
+	return apps, names, nil
+}
+
+func findTeamApplication(tx *gorm.DB, teamApplicationID int) (*model.TeamApplication, error) {
+	var teamApp model.TeamApplication
+	if err := tx.First(&teamApp, "team_application_id = ?", teamApplicationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("团队报名不存在")
+		}
+		return nil, errors.New("查询团队报名失败")
+	}
+	return &teamApp, nil
+}
+
+// ReviewTeamApplication 整体审核团队报名：批准时全体成员须同时满足名额和时间冲突检查，否则整体不批准
+func ReviewTeamApplication(teamApplicationID int, status string, handlerID int, comment string, overrideConflict bool) error {
+	status, comment, err := normalizeReview(status, comment)
+	if err != nil {
+		return err
+	}
+	if err := requireConflictOverride(overrideConflict, &handlerID); err != nil {
+		return err
+	}
+
+	return transaction(func(tx *gorm.DB) error {
+		teamApp, err := findTeamApplication(tx, teamApplicationID)
+		if err != nil {
+			return err
+		}
+
+		var activity model.Activity
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&activity, "activity_id = ?", teamApp.ActivityID).Error; err != nil {
+			return errors.New("查询活动信息失败")
+		}
+
+		apps, names, err := loadTeamApplicationMembers(tx, teamApplicationID)
+		if err != nil {
+			return err
+		}
+		if len(apps) == 0 {
+			return errors.New("团队报名中没有可审核的成员报名")
+		}
+
+		if status == model.AppStatusApproved {
+			var toApprove []*model.Application
+			for i := range apps {
+				if apps[i].CurrentStatus != model.AppStatusApproved && apps[i].CurrentStatus != model.AppStatusAttended {
+					toApprove = append(toApprove, &apps[i])
+				}
+			}
+			holders, err := countSlotHolders(tx, activity.ActivityID)
+			if err != nil {
+				return err
+			}
+			if remaining := activity.MaxPeople - int(holders); remaining < len(toApprove) {
+				return fmt.Errorf("活动剩余名额不足（剩余 %d 个，待批准 %d 人），无法整体批准", max(remaining, 0), len(toApprove))
+			}
+
+			for _, app := range toApprove {
+				note, err := checkTimeConflict(tx, app.UserID, &activity, slotHoldingStatuses, overrideConflict)
+				if err != nil {
+					return memberError(names[app.UserID], err)
+				}
+				if err := changeApplicationStatusWithNote(tx, app, status, &handlerID, comment, note); err != nil {
+					return memberError(names[app.UserID], err)
+				}
+			}
+			return nil
+		}
+
+		for i := range apps {
+			app := &apps[i]
+			if app.CurrentStatus == status {
+				continue
+			}
+			if err := changeApplicationStatus(tx, app, status, &handlerID, comment); err != nil {
+				return memberError(names[app.UserID], err)
+			}
+		}
+		return nil
+	})
+}
+
+// CancelTeamApplication 队长（或管理员）取消团队报名，全体成员的报名一并撤回
+func CancelTeamApplication(teamApplicationID int, req *model.CancelTeamApplicationRequest) error {
+	teamApp, err := findTeamApplication(config.DB, teamApplicationID)
+	if err != nil {
+		return err
+	}
+	team, err := findTeam(teamApp.TeamID)
+	if err != nil {
+		return err
+	}
+	if team.LeaderID != req.UserID {
+		admin, err := isAdmin(req.UserID)
+		if err != nil {
+			return err
+		}
+		if !admin {
+			return errors.New("只有队长或管理员可以取消团队报名")
+		}
+	}
+
+	// 与个人撤回一致，活动开始后不能取消，成员仍按考勤结果处理
+	var activity model.Activity
+	if err := config.DB.Select("activity_id", "activity_time").
+		First(&activity, "activity_id = ?", teamApp.ActivityID).Error; err != nil {
+		return errors.New("查询活动信息失败")
+	}
+	if !time.Now().Before(activity.ActivityTime) {
+		return errors.New("活动已开始，不能取消团队报名")
+	}
+
+	reason, err := normalizeComment(req.Reason, "取消原因")
+	if err != nil {
+		return err
+	}
+	comment := joinComment("团队取消报名", reason)
+	return transaction(func(tx *gorm.DB) error {
+		apps, names, err := loadTeamApplicationMembers(tx, teamApplicationID)
+		if err != nil {
+			return err
+		}
+
+		cancelled := 0
+		for i := range apps {
+			app := &apps[i]
+			if !canTransition(app.CurrentStatus, model.AppStatusWithdrawn) {
+				continue
+			}
+			if err := changeApplicationStatus(tx, app, model.AppStatusWithdrawn, &req.UserID, comment); err != nil {
+				return memberError(names[app.UserID], err)
+			}
+			cancelled++
+		}
+		if cancelled == 0 {
+			return errors.New("团队报名中没有可取消的成员报名")
+		}
+		return nil
+	})
+}
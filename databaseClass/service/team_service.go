@@ -0,0 +1,172 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/model"
+
+	"gorm.io/gorm"
+)
+
+// CreateTeam 创建团队，队长自动成为成员
+func CreateTeam(req *model.CreateTeamRequest) (*model.TeamInfo, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("团队名称不能为空")
+	}
+
+	memberIDs := uniqueInts(append([]int{req.LeaderID}, req.MemberIDs...))
+	var count int64
+	if err := config.DB.Model(&model.User{}).Where("user_id IN ?", memberIDs).Count(&count).Error; err != nil {
+		return nil, errors.New("查询用户失败")
+	}
+	if count != int64(len(memberIDs)) {
+		return nil, errors.New("队长或成员中有用户不存在")
+	}
+
+	team := model.Team{
+		Name:      name,
+		LeaderID:  req.LeaderID,
+		CreatedAt: time.Now(),
+	}
+	err := transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&team).Error; err != nil {
+			return errors.New("创建团队失败")
+		}
+		for _, userID := range memberIDs {
+			member := model.TeamMember{TeamID: team.TeamID, UserID: userID, JoinedAt: team.CreatedAt}
+			if err := tx.Create(&member).Error; err != nil {
+				return errors.New("添加团队成员失败")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetTeam(team.TeamID)
+}
+
+func findTeam(teamID int) (*model.Team, error) {
+	var team model.Team
+	if err := config.DB.First(&team, "team_id = ?", teamID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("团队不存在")
+		}
+		return nil, errors.New("查询团队失败")
+	}
+	return &team, nil
+}
+
+// listTeamMembers 查询团队成员（按加入时间排序）
+func listTeamMembers(teamID int) ([]model.TeamMemberInfo, error) {
+	var members []model.TeamMemberInfo
+	if err := config.DB.Table("TeamMember m").
+		Select("m.user_id, u.username, m.joined_at").
+		Joins("JOIN User u ON m.user_id = u.user_id").
+		Where("m.team_id = ?", teamID).
+		Order("m.joined_at ASC, m.user_id ASC").
+		Scan(&members).Error; err != nil {
+		return nil, errors.New("查询团队成员失败")
+	}
+	return members, nil
+}
+
+// GetTeam 查询团队及成员
+func GetTeam(teamID int) (*model.TeamInfo, error) {
+	team, err := findTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &model.TeamInfo{Team: *team}
+	if info.Members, err = listTeamMembers(teamID); err != nil {
+		return nil, err
+	}
+	for _, m := range info.Members {
+		if m.UserID == team.LeaderID {
+			info.LeaderName = m.Username
+		}
+	}
+	return info, nil
+}
+
+// ListUserTeams 查询用户所在的团队
+func ListUserTeams(userID int) ([]model.TeamInfo, error) {
+	var teamIDs []int
+	if err := config.DB.Model(&model.TeamMember{}).
+		Where("user_id = ?", userID).
+		Order("team_id ASC").
+		Pluck("team_id", &teamIDs).Error; err != nil {
+		return nil, errors.New("查询团队失败")
+	}
+
+	teams := make([]model.TeamInfo, 0, len(teamIDs))
+	for _, id := range teamIDs {
+		team, err := GetTeam(id)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, *team)
+	}
+	return teams, nil
+}
+
+// AddTeamMember 队长添加团队成员
+func AddTeamMember(teamID int, req *model.TeamMemberRequest) error {
+	team, err := findTeam(teamID)
+	if err != nil {
+		return err
+	}
+	if team.LeaderID != req.HandlerID {
+		return errors.New("只有队长可以添加成员")
+	}
+
+	var user model.User
+	if err := config.DB.First(&user, "user_id = ?", req.UserID).Error; err != nil {
+		return errors.New("用户不存在")
+	}
+
+	var count int64
+	if err := config.DB.Model(&model.TeamMember{}).
+		Where("team_id = ? AND user_id = ?", teamID, req.UserID).
+		Count(&count).Error; err != nil {
+		return errors.New("查询团队成员失败")
+	}
+	if count > 0 {
+		return errors.New("该用户已是团队成员")
+	}
+
+	member := model.TeamMember{TeamID: teamID, UserID: req.UserID, JoinedAt: time.Now()}
+	if err := config.DB.Create(&member).Error; err != nil {
+		return errors.New("添加团队成员失败")
+	}
+	return nil
+}
+
+// RemoveTeamMember 队长移除成员或成员自行退出；已提交的团队报名不受影响
+func RemoveTeamMember(teamID, userID, handlerID int) error {
+	team, err := findTeam(teamID)
+	if err != nil {
+		return err
+	}
+	if handlerID != team.LeaderID && handlerID != userID {
+		return errors.New("只有队长或成员本人可以移除成员")
+	}
+	if userID == team.LeaderID {
+		return errors.New("队长不能退出团队")
+	}
+
+	result := config.DB.Delete(&model.TeamMember{}, "team_id = ? AND user_id = ?", teamID, userID)
+	if result.Error != nil {
+		return errors.New("移除团队成员失败")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("该用户不是团队成员")
+	}
+	return nil
+}
@@ -2,16 +2,19 @@ package service
 
 import (
 	"errors"
+	"net/mail"
 	"strings"
+	"time"
 
 	"volunteer-system/config"
+	"volunteer-system/mailer"
 	"volunteer-system/model"
 	"volunteer-system/utils"
 
 	"gorm.io/gorm"
 )
 
-func Register(username, password, roleName string) (*model.LoginResponse, error) {
+func Register(username, password, roleName string, deptID *int, email string) (*model.LoginResponse, error) {
 	var existing model.User
 	if err := config.DB.Where("username = ?", username).First(&existing).Error; err == nil {
 		return nil, errors.New("用户名已存在")
@@ -30,10 +33,24 @@ func Register(username, password, roleName string) (*model.LoginResponse, error)
 		return nil, errors.New("角色不存在")
 	}
 
+	if deptID != nil {
+		var dept model.Dept
+		if err := config.DB.First(&dept, "dept_id = ?", *deptID).Error; err != nil {
+			return nil, errors.New("部门不存在")
+		}
+	}
+
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
 	user := model.User{
 		RoleID:   role.RoleID,
 		Username: username,
 		Password: utils.MD5Hash(password),
+		DeptID:   deptID,
+		Email:    email,
 	}
 
 	if err := config.DB.Create(&user).Error; err != nil {
@@ -70,3 +87,138 @@ func Login(username, password string) (*model.LoginResponse, error) {
 		RoleID:   user.RoleID,
 	}, nil
 }
+
+// adminRoleName 管理员角色名
+const adminRoleName = "管理员"
+
+// isAdmin 判断用户是否为管理员
+func isAdmin(userID int) (bool, error) {
+	var count int64
+	if err := config.DB.Table("User").
+		Joins("JOIN Role ON User.role_id = Role.role_id").
+		Where("User.user_id = ? AND Role.role_name = ?", userID, adminRoleName).
+		Count(&count).Error; err != nil {
+		return false, errors.New("查询用户角色失败")
+	}
+	return count > 0, nil
+}
+
+// requireAdmin 检查操作者是否为管理员，action用于错误提示
+func requireAdmin(handlerID int, action string) error {
+	admin, err := isAdmin(handlerID)
+	if err != nil {
+		return err
+	}
+	if !admin {
+		return &CodedError{Code: ErrCodeForbidden, Message: "只有管理员可以" + action}
+	}
+	return nil
+}
+
+// UpdateUserDept 设置用户所属部门（用于部门资格规则）
+func UpdateUserDept(userID int, deptID *int) error {
+	var user model.User
+	if err := config.DB.First(&user, "user_id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+		return errors.New("查询用户失败")
+	}
+
+	if deptID != nil {
+		var dept model.Dept
+		if err := config.DB.First(&dept, "dept_id = ?", *deptID).Error; err != nil {
+			return errors.New("部门不存在")
+		}
+	}
+
+	if err := config.DB.Model(&model.User{}).
+		Where("user_id = ?", userID).
+		Update("dept_id", deptID).Error; err != nil {
+		return errors.New("更新用户部门失败")
+	}
+	return nil
+}
+
+// AddUserTraining 记录用户完成的培训（用于培训资格规则）
+func AddUserTraining(userID int, req *model.AddUserTrainingRequest) (*model.UserTraining, error) {
+	var user model.User
+	if err := config.DB.First(&user, "user_id = ?", userID).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	completedAt := time.Now()
+	if req.CompletedAt != "" {
+		t, err := utils.ParseActivityTime(req.CompletedAt)
+		if err != nil {
+			return nil, errors.New("完成时间格式不正确")
+		}
+		completedAt = t
+	}
+
+	training := model.UserTraining{
+		UserID:       userID,
+		TrainingName: strings.TrimSpace(req.TrainingName),
+		CompletedAt:  completedAt,
+	}
+	if training.TrainingName == "" {
+		return nil, errors.New("培训名称不能为空")
+	}
+
+	if err := config.DB.Create(&training).Error; err != nil {
+		return nil, errors.New("保存培训记录失败")
+	}
+	return &training, nil
+}
+
+// ListUserTrainings 查询用户的培训记录
+func ListUserTrainings(userID int) ([]model.UserTraining, error) {
+	var trainings []model.UserTraining
+	if err := config.DB.Where("user_id = ?", userID).
+		Order("completed_at DESC").
+		Find(&trainings).Error; err != nil {
+		return nil, errors.New("查询培训记录失败")
+	}
+	return trainings, nil
+}
+
+// normalizeEmail 校验邮箱格式，允许为空
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", nil
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", errors.New("邮箱格式不正确")
+	}
+	return email, nil
+}
+
+// UpdateUserContact 设置用户的邮箱和邮件语言
+func UpdateUserContact(userID int, req *model.UpdateUserContactRequest) error {
+	var user model.User
+	if err := config.DB.First(&user, "user_id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+		return errors.New("查询用户失败")
+	}
+
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return err
+	}
+	locale := strings.TrimSpace(req.Locale)
+	if locale != "" && !mailer.IsSupportedLocale(locale) {
+		return errors.New("不支持的语言：" + locale)
+	}
+
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"email":  email,
+		"locale": locale,
+	}).Error; err != nil {
+		return errors.New("更新联系方式失败")
+	}
+	return nil
+}
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"volunteer-system/config"
+	"volunteer-system/model"
+
+	"gorm.io/gorm"
+)
+
+// webhookBatchSize 每次任务最多投递的Webhook数
+const webhookBatchSize = 100
+
+// maxWebhookErrorLength 投递记录中错误信息的最大长度（字符数）
+const maxWebhookErrorLength = 1000
+
+var webhookEvents = map[string]bool{
+	model.WebhookEventActivityCreated:     true,
+	model.WebhookEventActivityCancelled:   true,
+	model.WebhookEventApplicationApproved: true,
+	model.WebhookEventAll:                 true,
+}
+
+func randomHex(n int) (string, error) {
+	raw := make([]byte, n)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+func truncateError(msg string) string {
+	runes := []rune(msg)
+	if len(runes) > maxWebhookErrorLength {
+		runes = runes[:maxWebhookErrorLength]
+	}
+	return string(runes)
+}
+
+// signWebhook 签名为 HMAC-SHA256(secret, 时间戳 + "." + 请求体) 的十六进制
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// publishWebhookEvent 在tx中为订阅了该事件的每个订阅写入一条待投递记录，随业务数据一起提交
+func publishWebhookEvent(tx *gorm.DB, event string, data interface{}) error {
+	var subscriptions []model.WebhookSubscription
+	if err := tx.Where("active = ?", true).Find(&subscriptions).Error; err != nil {
+		return errors.New("查询Webhook订阅失败")
+	}
+
+	var matched []model.WebhookSubscription
+	for _, sub := range subscriptions {
+		if sub.Events.Matches(event) {
+			matched = append(matched, sub)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	eventID, err := randomHex(16)
+	if err != nil {
+		return errors.New("生成事件ID失败")
+	}
+	now := time.Now()
+	payload, err := json.Marshal(map[string]interface{}{
+		"event_id":    eventID,
+		"event":       event,
+		"occurred_at": now,
+		"data":        data,
+	})
+	if err != nil {
+		return errors.New("生成Webhook内容失败")
+	}
+
+	for _, sub := range matched {
+		delivery := model.WebhookDelivery{
+			SubscriptionID: sub.SubscriptionID,
+			EventID:        eventID,
+			Event:          event,
+			Payload:        string(payload),
+			Status:         model.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		}
+		if err := tx.Create(&delivery).Error; err != nil {
+			return errors.New("保存Webhook投递失败")
+		}
+	}
+	return nil
+}
+
+// postWebhook 发送一次Webhook请求，返回HTTP状态码
+func postWebhook(ctx context.Context, client *http.Client, sub *model.WebhookSubscription, delivery *model.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "volunteer-system-webhook")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Event-Id", delivery.EventID)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.DeliveryID, 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+signWebhook(sub.Secret, timestamp, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("对方返回状态码 %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// DeliverWebhooks 定时任务：投递到期的Webhook，记录每次尝试，失败的按指数退避重试，返回投递成功数
+func DeliverWebhooks(ctx context.Context) (int64, error) {
+	var deliveries []model.WebhookDelivery
+	if err := config.DB.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryPending, time.Now()).
+		Order("delivery_id ASC").
+		Limit(webhookBatchSize).
+		Find(&deliveries).Error; err != nil {
+		return 0, errors.New("查询待投递的Webhook失败")
+	}
+
+	client := &http.Client{Timeout: config.Webhook.Timeout}
+	subscriptions := make(map[int]*model.WebhookSubscription)
+
+	var delivered int64
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+		delivery := &deliveries[i]
+
+		sub, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			var s model.WebhookSubscription
+			if err := config.DB.First(&s, "subscription_id = ?", delivery.SubscriptionID).Error; err == nil {
+				sub = &s
+			}
+			subscriptions[delivery.SubscriptionID] = sub
+		}
+
+		start := time.Now()
+		var statusCode int
+		var sendErr error
+		if sub == nil || !sub.Active {
+			sendErr = errors.New("订阅已停用")
+		} else {
+			statusCode, sendErr = postWebhook(ctx, client, sub, delivery)
+		}
+
+		attempt := model.WebhookAttempt{
+			DeliveryID:  delivery.DeliveryID,
+			AttemptedAt: start,
+			StatusCode:  statusCode,
+			DurationMs:  time.Since(start).Milliseconds(),
+		}
+		updates := map[string]interface{}{
+			"attempts":         delivery.Attempts + 1,
+			"last_status_code": statusCode,
+		}
+		if sendErr == nil {
+			updates["status"] = model.WebhookDeliverySucceeded
+			updates["delivered_at"] = time.Now()
+			updates["last_error"] = ""
+			delivered++
+		} else {
+			attempt.Error = truncateError(sendErr.Error())
+			updates["last_error"] = attempt.Error
+			if sub == nil || !sub.Active || delivery.Attempts+1 >= config.Webhook.MaxAttempts {
+				updates["status"] = model.WebhookDeliveryFailed
+			} else {
+				updates["next_attempt_at"] = time.Now().Add(
+					backoffDelay(delivery.Attempts+1, config.Webhook.RetryBaseDelay, config.Webhook.RetryMaxDelay))
+			}
+		}
+
+		err := transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&attempt).Error; err != nil {
+				return err
+			}
+			return tx.Model(delivery).Updates(updates).Error
+		})
+		if err != nil {
+			log.Printf("记录Webhook投递结果失败 (投递ID:%d): %v", delivery.DeliveryID, err)
+		}
+	}
+	return delivered, nil
+}
+
+// CreateWebhook 管理员创建Webhook订阅，密钥为空时自动生成，密钥只在创建时返回
+func CreateWebhook(req *model.CreateWebhookRequest) (*model.WebhookSubscriptionInfo, error) {
+	if err := requireAdmin(req.HandlerID, "管理Webhook"); err != nil {
+		return nil, err
+	}
+
+	target, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, errors.New("Webhook地址必须是http或https URL")
+	}
+
+	if len(req.Events) == 0 {
+		return nil, errors.New("至少订阅一个事件")
+	}
+	events := make(model.WebhookEvents, 0, len(req.Events))
+	for _, event := range req.Events {
+		event = strings.TrimSpace(event)
+		if !webhookEvents[event] {
+			return nil, errors.New("不支持的事件：" + event)
+		}
+		events = append(events, event)
+	}
+
+	secret := strings.TrimSpace(req.Secret)
+	if secret == "" {
+		if secret, err = randomHex(32); err != nil {
+			return nil, errors.New("生成签名密钥失败")
+		}
+	}
+
+	sub := model.WebhookSubscription{
+		URL:       target.String(),
+		Events:    events,
+		Secret:    secret,
+		Active:    true,
+		CreatedBy: req.HandlerID,
+		CreatedAt: time.Now(),
+	}
+	if err := config.DB.Create(&sub).Error; err != nil {
+		return nil, errors.New("创建Webhook订阅失败")
+	}
+	return &model.WebhookSubscriptionInfo{WebhookSubscription: sub, Secret: secret}, nil
+}
+
+// ListWebhooks 管理员查看所有Webhook订阅
+func ListWebhooks(handlerID int) ([]model.WebhookSubscription, error) {
+	if err := requireAdmin(handlerID, "管理Webhook"); err != nil {
+		return nil, err
+	}
+
+	var subs []model.WebhookSubscription
+	if err := config.DB.Order("subscription_id DESC").Find(&subs).Error; err != nil {
+		return nil, errors.New("查询Webhook订阅失败")
+	}
+	return subs, nil
+}
+
+func findWebhook(subscriptionID int) (*model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+	if err := config.DB.First(&sub, "subscription_id = ?", subscriptionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Webhook订阅不存在")
+		}
+		return nil, errors.New("查询Webhook订阅失败")
+	}
+	return &sub, nil
+}
+
+// DeactivateWebhook 停用Webhook订阅，保留投递记录，未投递的不再发送
+func DeactivateWebhook(subscriptionID, handlerID int) error {
+	if err := requireAdmin(handlerID, "管理Webhook"); err != nil {
+		return err
+	}
+	sub, err := findWebhook(subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	if err := config.DB.Model(sub).Update("active", false).Error; err != nil {
+		return errors.New("停用Webhook订阅失败")
+	}
+	return nil
+}
+
+// ListWebhookDeliveries 查看订阅的投递记录及每次尝试（status为空查看全部，limit默认50）
+func ListWebhookDeliveries(subscriptionID, handlerID int, status string, limit int) ([]model.WebhookDeliveryInfo, error) {
+	if err := requireAdmin(handlerID, "管理Webhook"); err != nil {
+		return nil, err
+	}
+	if _, err := findWebhook(subscriptionID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	query := config.DB.Model(&model.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var deliveries []model.WebhookDeliveryInfo
+	if err := query.Order("delivery_id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, errors.New("查询Webhook投递记录失败")
+	}
+
+	for i := range deliveries {
+		if err := config.DB.Where("delivery_id = ?", deliveries[i].DeliveryID).
+			Order("attempt_id ASC").
+			Find(&deliveries[i].AttemptLog).Error; err != nil {
+			return nil, errors.New("查询Webhook投递尝试失败")
+		}
+	}
+	return deliveries, nil
+}
+
+// replayDeliveries 将投递记录重新置为待投递，重新计算尝试次数
+func replayDeliveries(query *gorm.DB) (int64, error) {
+	result := query.Updates(map[string]interface{}{
+		"status":          model.WebhookDeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	})
+	if result.Error != nil {
+		return 0, errors.New("重放Webhook投递失败")
+	}
+	return result.RowsAffected, nil
+}
+
+// ReplayWebhookDelivery 管理员重放一条已结束（失败或成功）的投递
+func ReplayWebhookDelivery(deliveryID int64, handlerID int) error {
+	if err := requireAdmin(handlerID, "管理Webhook"); err != nil {
+		return err
+	}
+
+	var delivery model.WebhookDelivery
+	if err := config.DB.First(&delivery, "delivery_id = ?", deliveryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("投递记录不存在")
+		}
+		return errors.New("查询投递记录失败")
+	}
+	if delivery.Status == model.WebhookDeliveryPending {
+		return errors.New("投递正在等待发送，无需重放")
+	}
+	sub, err := findWebhook(delivery.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	if !sub.Active {
+		return errors.New("订阅已停用，不能重放")
+	}
+
+	_, err = replayDeliveries(config.DB.Model(&model.WebhookDelivery{}).
+		Where("delivery_id = ? AND status = ?", deliveryID, delivery.Status))
+	return err
+}
+
+// ReplayFailedWebhookDeliveries 管理员重放订阅下所有失败的投递，返回重放的条数
+func ReplayFailedWebhookDeliveries(subscriptionID, handlerID int) (int64, error) {
+	if err := requireAdmin(handlerID, "管理Webhook"); err != nil {
+		return 0, err
+	}
+	sub, err := findWebhook(subscriptionID)
+	if err != nil {
+		return 0, err
+	}
+	if !sub.Active {
+		return 0, errors.New("订阅已停用，不能重放")
+	}
+
+	return replayDeliveries(config.DB.Model(&model.WebhookDelivery{}).
+		Where("subscription_id = ? AND status = ?", subscriptionID, model.WebhookDeliveryFailed))
+}
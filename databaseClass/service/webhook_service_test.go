@@ -0,0 +1,38 @@
+package service
+
+import "testing"
+
+func TestSignWebhook(t *testing.T) {
+	const body = `{"event":"activity.created"}`
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      string
+		want      string
+	}{
+		{"普通请求", "secret", "1700000000", body, "d5884459f58fa1f4d6a0436d8cd15a6e3c6f5380b0561471f729bc26efa17e67"},
+		{"空请求体", "secret", "1700000000", "", "4bc5f74d868b97888288889c5d9d65df02526f94c1592a79fdf4fe8b26e311e5"},
+		{"空密钥", "", "0", "{}", "4fa6c2486692767ff3eb0ad23d9638df613add15a49b8ffc0a606879b90a6f25"},
+		{"非ASCII密钥", "密钥", "1700000001", body, "02d4b9e83b8bb06c293206df298de0401338b43f0f8950125a7c2068a7cfe89e"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := signWebhook(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+				t.Errorf("signWebhook(%q, %q, %q) = %s, want %s", tt.secret, tt.timestamp, tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+// 时间戳和请求体之间有分隔符，移动两者的边界会得到不同的签名
+func TestSignWebhookSeparatesTimestamp(t *testing.T) {
+	a := signWebhook("secret", "17000000001", []byte("{}"))
+	b := signWebhook("secret", "1700000000", []byte("1{}"))
+	if a == b {
+		t.Error("时间戳与请求体的边界不同，签名却相同")
+	}
+	if signWebhook("secret", "1700000000", []byte("{}")) == signWebhook("other", "1700000000", []byte("{}")) {
+		t.Error("不同密钥得到了相同的签名")
+	}
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf16"
+)
+
+// A4纸尺寸（单位：pt）
+const (
+	PDFPageWidth  = 595.0
+	PDFPageHeight = 842.0
+)
+
+// PDFDocument 极简PDF生成器，只支持文字和直线，用于生成证明和成绩单。
+// 文字使用PDF阅读器内置的中文字体 STSong-Light（Adobe-GB1），不嵌入字体文件。
+type PDFDocument struct {
+	pages []*bytes.Buffer
+}
+
+func NewPDFDocument() *PDFDocument {
+	doc := &PDFDocument{}
+	doc.AddPage()
+	return doc
+}
+
+// AddPage 新增一页，之后的绘制都在新页上
+func (d *PDFDocument) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *PDFDocument) current() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// TextWidth 估算文字宽度：ASCII字符半角，其余全角
+func TextWidth(text string, size float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		if r < 0x80 {
+			width += 500
+		} else {
+			width += 1000
+		}
+	}
+	return width * size / 1000
+}
+
+// Text 在(x, y)处绘制文字，坐标原点为页面左下角
+func (d *PDFDocument) Text(x, y, size float64, text string) {
+	fmt.Fprintf(d.current(), "BT /F1 %.2f Tf %.2f %.2f Td <%s> Tj ET\n", size, x, y, encodeUCS2(text))
+}
+
+// TextCenter 在页面水平居中绘制文字
+func (d *PDFDocument) TextCenter(y, size float64, text string) {
+	d.Text((PDFPageWidth-TextWidth(text, size))/2, y, size, text)
+}
+
+// Paragraph 在指定宽度内自动换行绘制文字，返回下一行的y坐标
+func (d *PDFDocument) Paragraph(x, y, width, size, lineHeight float64, text string) float64 {
+	line := []rune{}
+	for _, r := range text {
+		candidate := append(line, r)
+		if len(line) > 0 && TextWidth(string(candidate), size) > width {
+			d.Text(x, y, size, string(line))
+			y -= lineHeight
+			line = []rune{r}
+			continue
+		}
+		line = candidate
+	}
+	if len(line) > 0 {
+		d.Text(x, y, size, string(line))
+		y -= lineHeight
+	}
+	return y
+}
+
+// Line 绘制直线
+func (d *PDFDocument) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.current(), "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, y1, x2, y2)
+}
+
+// Rect 绘制矩形边框
+func (d *PDFDocument) Rect(x, y, w, h, width float64) {
+	fmt.Fprintf(d.current(), "%.2f w %.2f %.2f %.2f %.2f re S\n", width, x, y, w, h)
+}
+
+// Bytes 输出完整的PDF文件内容
+func (d *PDFDocument) Bytes() []byte {
+	var out bytes.Buffer
+	offsets := []int{}
+	writeObject := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1: Catalog, 2: Pages, 3-5: 字体, 之后每页两个对象（Page + Contents）
+	const firstPageObject = 6
+	kids := &bytes.Buffer{}
+	for i := range d.pages {
+		fmt.Fprintf(kids, "%d 0 R ", firstPageObject+i*2)
+	}
+
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids.String(), len(d.pages)))
+	writeObject("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>")
+	writeObject("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> " +
+		"/FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>")
+	writeObject("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] " +
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+
+	for i, page := range d.pages {
+		contentObject := firstPageObject + i*2 + 1
+		writeObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", PDFPageWidth, PDFPageHeight, contentObject))
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+// encodeUCS2 将文字编码为UTF-16BE十六进制串，供UniGB-UCS2-H编码使用
+func encodeUCS2(text string) string {
+	var sb bytes.Buffer
+	for _, unit := range utf16.Encode([]rune(text)) {
+		fmt.Fprintf(&sb, "%04X", unit)
+	}
+	return sb.String()
+}
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"crypto/md5"
+	"crypto/rand"
 	"fmt"
+	"math/big"
 	"strings"
 	"time"
 )
@@ -32,3 +34,20 @@ func ParseActivityTime(raw string) (time.Time, error) {
 	}
 	return time.Time{}, lastErr
 }
+
+// 随机码字符集，去掉了容易混淆的 0/O/1/I
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// RandomCode 生成指定长度的随机码（大写字母和数字）
+func RandomCode(length int) (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(codeAlphabet)))
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(codeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
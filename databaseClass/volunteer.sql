@@ -80,7 +80,7 @@ CREATE TABLE Application
    user_id              INT NOT NULL,
    activity_id          INT NOT NULL,
    apply_time           DATETIME NOT NULL,
-   current_status       VARCHAR(20) NOT NULL DEFAULT 'pending',
+   current_status       VARCHAR(32) NOT NULL DEFAULT 'pending',
    PRIMARY KEY (application_id),
    UNIQUE KEY uk_user_activity (user_id, activity_id)  -- 防止重复申请
 );
@@ -93,7 +93,7 @@ CREATE TABLE ApplicationStatusLog
    log_id               INT NOT NULL AUTO_INCREMENT,
    application_id       INT NOT NULL,
    handler_id           INT NULL,  -- 改名为handler_id更明确，允许NULL
-   log_status           VARCHAR(20) NOT NULL,
+   log_status           VARCHAR(32) NOT NULL,
    handle_time          DATETIME NOT NULL,
    PRIMARY KEY (log_id)
 );